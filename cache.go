@@ -34,7 +34,7 @@ func init() {
 	cacheDir := filepath.Join(home, ".local", "share", "compaa", Version)
 	cacheFile = filepath.Join(cacheDir, ".cache")
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		fmt.Println("Warn: fails creating cache directory:", err)
+		fmt.Fprintln(os.Stderr, "Warn: fails creating cache directory:", err)
 	}
 }
 
@@ -57,14 +57,14 @@ func NewCache() *Cache {
 		entries: make(map[string]*CacheEntry),
 	}
 	if err := c.LoadFromFile(cacheFile); err != nil {
-		fmt.Println("Warn: fails loading cache:", err)
+		fmt.Fprintln(os.Stderr, "Warn: fails loading cache:", err)
 	}
 	return c
 }
 
 func (c *Cache) Close() {
 	if err := c.SaveToFile(cacheFile); err != nil {
-		fmt.Println("Warn: fails saving cache:", err)
+		fmt.Fprintln(os.Stderr, "Warn: fails saving cache:", err)
 	}
 }
 
@@ -161,6 +161,10 @@ func decompressedIOReader(b []byte) io.Reader {
 }
 
 func (c *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// token exchanges, logins and responses to credentials must not be persisted, nor shared between scopes or users
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" {
+		return c.Transport.RoundTrip(req)
+	}
 	if entry, found := c.Cache.Get(req.URL.String()); found {
 		if entry.Expire.After(time.Now()) {
 			resp := &http.Response{
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_CacheTransportSkipsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=3600")
+		io.WriteString(w, r.Method+" "+r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	cache := &Cache{entries: map[string]*CacheEntry{}}
+	cli := &http.Client{Transport: &CacheTransport{Transport: http.DefaultTransport, Cache: cache}}
+	do := func(method, auth string) string {
+		req, err := http.NewRequest(method, srv.URL+"/token", strings.NewReader(""))
+		assert.NilError(t, err)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		res, err := cli.Do(req)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		return string(b)
+	}
+
+	assert.Equal(t, do(http.MethodPost, ""), "POST ")
+	assert.Equal(t, do(http.MethodGet, "Bearer secret"), "GET Bearer secret")
+	assert.Equal(t, len(cache.entries), 0)
+
+	assert.Equal(t, do(http.MethodGet, ""), "GET ")
+	assert.Equal(t, len(cache.entries), 1)
+	// a cached response doesn't answer requests with credentials
+	assert.Equal(t, do(http.MethodGet, "Bearer other"), "GET Bearer other")
+}
+
+func Test_TokenTransportBeneathCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=3600")
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	cache := &Cache{entries: map[string]*CacheEntry{}}
+	cli := &http.Client{Transport: &CacheTransport{Transport: &tokenTransport{Transport: http.DefaultTransport, Token: "t"}, Cache: cache}}
+	res, err := cli.Get(srv.URL + "/repos/org/repo")
+	assert.NilError(t, err)
+	b, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, string(b), "Bearer t")
+	assert.Equal(t, len(cache.entries), 1)
+}
@@ -1,7 +1,8 @@
 package component
 
 type Component interface {
-	Logging(wc *WarnCondition, logger Logger)
+	Evaluate(p *Policy) *Result
+	Logging(p *Policy, logger Logger)
 	LoadCache() bool
 	StoreCache()
 }
@@ -0,0 +1,177 @@
+package component
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ignore silences findings of matching components until the given date.
+// Name and Path are globs, where "*" does not cross "/" and "**" does.
+// Path is relative to the directory of the policy file.
+type Ignore struct {
+	Name      string
+	Ecosystem string
+	Path      string
+	Rule      string
+	Reason    string
+	Until     time.Time
+
+	name *regexp.Regexp
+	path *regexp.Regexp
+}
+
+type ignoreFile struct {
+	Name      string `yaml:"name"`
+	Ecosystem string `yaml:"ecosystem"`
+	Path      string `yaml:"path"`
+	Rule      string `yaml:"rule"`
+	Reason    string `yaml:"reason"`
+	Until     string `yaml:"until"`
+}
+
+func newIgnore(f *ignoreFile) (*Ignore, error) {
+	if f.Name == "" && f.Ecosystem == "" && f.Path == "" {
+		return nil, fmt.Errorf("ignore needs at least one of name, ecosystem or path")
+	}
+	if strings.TrimSpace(f.Reason) == "" {
+		return nil, fmt.Errorf("ignore %v needs a reason", f.Name+f.Path)
+	}
+	if _, ok := DefaultPolicy.Rules[f.Rule]; f.Rule != "" && !ok {
+		return nil, fmt.Errorf("ignore %v has unknown rule %v", f.Name+f.Path, f.Rule)
+	}
+	if f.Ecosystem != "" && !slices.Contains(ecosystems, f.Ecosystem) {
+		return nil, fmt.Errorf("ignore %v has unknown ecosystem %v", f.Name+f.Path, f.Ecosystem)
+	}
+	ig := &Ignore{
+		Name:      f.Name,
+		Ecosystem: f.Ecosystem,
+		Path:      f.Path,
+		Rule:      f.Rule,
+		Reason:    f.Reason,
+	}
+	if f.Until != "" {
+		until, err := time.Parse(time.DateOnly, f.Until)
+		if err != nil {
+			return nil, fmt.Errorf("ignore %v has invalid until: %w", f.Name+f.Path, err)
+		}
+		ig.Until = until
+	}
+	var err error
+	if ig.name, err = compileGlob(f.Name); err != nil {
+		return nil, err
+	}
+	if ig.path, err = compileGlob(filepath.ToSlash(f.Path)); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// Expired reports whether the ignore is no longer in effect. Until is inclusive.
+func (ig *Ignore) Expired(now time.Time) bool {
+	return !ig.Until.IsZero() && now.After(ig.Until.AddDate(0, 0, 1))
+}
+
+func (ig *Ignore) Match(path string, r *Result, f *Finding) bool {
+	if ig.Rule != "" && ig.Rule != f.Rule {
+		return false
+	}
+	if ig.Ecosystem != "" && ig.Ecosystem != r.Ecosystem {
+		return false
+	}
+	if ig.name != nil && !ig.name.MatchString(r.Name) {
+		return false
+	}
+	if ig.path != nil && !ig.path.MatchString(path) {
+		return false
+	}
+	return true
+}
+
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Suppress moves findings matched by an ignore entry into r.Suppressed.
+// Findings matched by an expired entry are kept and an expired-ignore finding is added,
+// so suppressions do not rot.
+func (p *Policy) Suppress(path string, r *Result) {
+	if len(p.Ignores) == 0 {
+		return
+	}
+	path = p.relPath(path)
+	now := time.Now()
+
+	var kept []Finding
+	var expired []*Ignore
+	for _, f := range r.Findings {
+		ig := p.matchIgnore(path, r, &f)
+		switch {
+		case ig == nil:
+			kept = append(kept, f)
+		case ig.Expired(now):
+			kept = append(kept, f)
+			if !slices.Contains(expired, ig) {
+				expired = append(expired, ig)
+			}
+		default:
+			r.Suppressed = append(r.Suppressed, Suppression{Finding: f, Reason: ig.Reason, Until: ig.Until})
+		}
+	}
+	r.Findings = kept
+	r.update()
+
+	if !p.Enabled(RuleExpiredIgnore) {
+		return
+	}
+	for _, ig := range expired {
+		r.Add(RuleExpiredIgnore, p.Severity(RuleExpiredIgnore), "ignore for %v expired on %v (%v)", r.Name, ig.Until.Format(time.DateOnly), ig.Reason)
+	}
+}
+
+func (p *Policy) matchIgnore(path string, r *Result, f *Finding) *Ignore {
+	for _, ig := range p.Ignores {
+		if ig.Match(path, r, f) {
+			return ig
+		}
+	}
+	return nil
+}
+
+func (p *Policy) relPath(path string) string {
+	if p.Dir != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(p.Dir, abs); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
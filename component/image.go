@@ -2,15 +2,21 @@ package component
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
 	"github.com/izziiyt/compaa/sdk/dockerhub"
+	"github.com/izziiyt/compaa/sdk/eol"
 	"github.com/izziiyt/compaa/sdk/gcrio"
+	"github.com/izziiyt/compaa/sdk/oci"
 )
 
 var (
@@ -28,12 +34,27 @@ type Image struct {
 	Namespace  string
 	Registry   string
 	Tag        string
+	Digest     string
+	// LiveTags are the tags which currently point at Digest.
+	LiveTags []string
+	// NewestTag is the newest tag in the same variant line as Tag, NewestPatch the newest patch release of its minor version.
+	NewestTag   string
+	NewestPatch string
+	// EOLCycle is the release the image is built on which reaches end of life first, like "debian 10".
+	EOLCycle   string
+	EOL        bool
+	EOLDate    time.Time
+	Line       int
 	Err        error
 	LastUpdate time.Time
 }
 
 type RegistryHandler interface {
-	ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string) (time.Time, error)
+	ReadTag(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, tag string) (time.Time, error)
+	// ReadDigest returns when digest was pushed, or zero if the registry doesn't tell,
+	// and which of tags, or of all tags if tags is empty, currently point at digest.
+	ReadDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, digest string, tags []string) (time.Time, []string, error)
+	ListTags(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository string) ([]string, error)
 }
 
 var registryHandlers = map[string]RegistryHandler{
@@ -43,63 +64,197 @@ var registryHandlers = map[string]RegistryHandler{
 
 type gcrioHandler struct{}
 
-func (h *gcrioHandler) ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string) (time.Time, error) {
-	r, err := gcrio.ReadTag(ctx, cli, namespace, repository, tag)
+func (h *gcrioHandler) ReadTag(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, tag string) (time.Time, error) {
+	r, err := gcrio.ReadTag(ctx, cli, namespace, repository, tag, cred)
 	if err != nil {
 		return time.Time{}, err
 	}
 	return r.Uploaded, nil
 }
 
+func (h *gcrioHandler) ReadDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, digest string, tags []string) (time.Time, []string, error) {
+	ms, err := gcrio.ListManifests(ctx, cli, namespace, repository, cred)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	m, ok := ms[digest]
+	if !ok {
+		return time.Time{}, nil, nil
+	}
+	var live []string
+	for _, t := range m.Tag {
+		if len(tags) == 0 || slices.Contains(tags, t) {
+			live = append(live, t)
+		}
+	}
+	return m.Uploaded, live, nil
+}
+
+func (h *gcrioHandler) ListTags(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository string) ([]string, error) {
+	ms, err := gcrio.ListManifests(ctx, cli, namespace, repository, cred)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, m := range ms {
+		tags = append(tags, m.Tag...)
+	}
+	return tags, nil
+}
+
 type dockerhubHandler struct{}
 
-func (h *dockerhubHandler) ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string) (time.Time, error) {
-	r, err := dockerhub.ReadTag(ctx, cli, namespace, repository, tag)
+func (h *dockerhubHandler) ReadTag(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, tag string) (time.Time, error) {
+	r, err := dockerhub.ReadTag(ctx, cli, namespace, repository, tag, cred)
 	if err != nil {
 		return time.Time{}, err
 	}
 	return r.LastUpdated, nil
 }
 
+// ReadDigest takes the earliest push among the tags pointing at digest, either as a whole or as one of their platform images.
+// Without tags, only the oci.MaxDigestTags most recently updated tags of the repository are checked.
+func (h *dockerhubHandler) ReadDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, digest string, tags []string) (time.Time, []string, error) {
+	var rs []*dockerhub.Response
+	if len(tags) == 0 {
+		var err error
+		if rs, err = dockerhub.ListRecentTags(ctx, cli, namespace, repository, oci.MaxDigestTags, cred); err != nil {
+			return time.Time{}, nil, err
+		}
+	}
+	for _, t := range tags {
+		r, err := dockerhub.ReadTag(ctx, cli, namespace, repository, t, cred)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		r.Name = t
+		rs = append(rs, r)
+	}
+
+	var pushed time.Time
+	var live []string
+	for _, r := range rs {
+		at := time.Time{}
+		if r.Digest == digest {
+			at = r.TagLastPushed
+		}
+		for _, i := range r.Images {
+			if i.Digest == digest {
+				at = i.LastPushed
+			}
+		}
+		if at.IsZero() {
+			continue
+		}
+		live = append(live, r.Name)
+		if pushed.IsZero() || at.Before(pushed) {
+			pushed = at
+		}
+	}
+	return pushed, live, nil
+}
+
+func (h *dockerhubHandler) ListTags(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository string) ([]string, error) {
+	rs, err := dockerhub.ListTags(ctx, cli, namespace, repository, cred)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, r := range rs {
+		tags = append(tags, r.Name)
+	}
+	return tags, nil
+}
+
+// ociHandler reads any registry which implements the OCI Distribution API.
+type ociHandler struct {
+	registry string
+}
+
+func (h *ociHandler) ReadTag(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, tag string) (time.Time, error) {
+	r, err := oci.ReadTag(ctx, cli, h.registry, path.Join(namespace, repository), tag, cred)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.Created, nil
+}
+
+// ReadDigest can't tell when digest was pushed, and compares the manifests of tags to find the ones pointing at digest.
+func (h *ociHandler) ReadDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, digest string, tags []string) (time.Time, []string, error) {
+	live, err := oci.DigestTags(ctx, cli, h.registry, path.Join(namespace, repository), digest, tags, cred)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return time.Time{}, live, nil
+}
+
+func (h *ociHandler) ListTags(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository string) ([]string, error) {
+	return oci.ListTags(ctx, cli, h.registry, path.Join(namespace, repository), cred)
+}
+
+// distributionHost is the host serving the OCI Distribution API of registry.
+func distributionHost(registry string) string {
+	if registry == DockerHubRegistry {
+		return "registry-1.docker.io"
+	}
+	return registry
+}
+
+// FromRawString parses s as an image reference. Namespace is the path without its last component,
+// which may be empty or span several components, e.g. "org/sub" of ghcr.io/org/sub/repo.
 func (c *Image) FromRawString(s string) *Image {
 	c.RawString = s
-	parts := strings.Split(s, "/")
-	switch len(parts) {
-	case 1: // golang:1.21.1-bullseye or golang@sha256:xxxx
-		c.Registry = DockerHubRegistry
-		c.Namespace = DockerHubOfficialNamespace
-		c.Repository = parts[0]
-	case 2: // lstio/base or lstio/base:tag or lstio/base@sha256:xxxx
-		c.Registry = DockerHubRegistry
-		c.Namespace = parts[0]
-		c.Repository = parts[1]
-	case 3: // gcr.io/distroless/base-nossl-debian11 or gcr.io/distroless/base-nossl-debian11:tag or gcr.io/distroless/base-nossl-debian11@sha256:xxxx
-		c.Registry = parts[0]
-		c.Namespace = parts[1]
-		c.Repository = parts[2]
-	default:
-		c.Err = fmt.Errorf("unsupported format: %v", s)
+	r, err := parseReference(s)
+	if err != nil {
+		c.Err = fmt.Errorf("invalid reference: %w", err)
 		return c
 	}
-
-	// Handle tag or digest
-	repoParts := strings.Split(c.Repository, "@")
-	if len(repoParts) == 2 {
-		c.Repository = repoParts[0]
-		c.Tag = "@" + repoParts[1] // Keep the digest format
+	c.Registry = r.domain
+	if i := strings.LastIndex(r.path, "/"); i >= 0 {
+		c.Namespace, c.Repository = r.path[:i], r.path[i+1:]
 	} else {
-		repoParts = strings.Split(c.Repository, ":")
-		if len(repoParts) == 2 {
-			c.Repository = repoParts[0]
-			c.Tag = repoParts[1]
-		} else {
-			c.Tag = DefaultTag
-		}
+		c.Repository = r.path
 	}
-
+	c.Tag = r.tag
+	c.Digest = r.digest
 	return c
 }
 
+// String returns the canonical reference like docker.io/library/alpine:3.19, or the raw string if it isn't parsed.
+func (c *Image) String() string {
+	if c.Repository == "" {
+		return c.RawString
+	}
+	r := &reference{domain: c.Registry, path: path.Join(c.Namespace, c.Repository), tag: c.Tag, digest: c.Digest}
+	return r.String()
+}
+
+// suggestion returns the tags to move to, formatted to follow a message.
+func (c *Image) suggestion() string {
+	var ss []string
+	if c.NewestTag != "" {
+		ss = append(ss, "newest "+c.NewestTag)
+	}
+	if c.NewestPatch != "" && c.NewestPatch != c.NewestTag {
+		ss = append(ss, "newest patch "+c.NewestPatch)
+	}
+	if len(ss) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(ss, ", ")
+}
+
+// version is the tag, the digest, or both joined as in the reference.
+func (c *Image) version() string {
+	if c.Digest == "" {
+		return c.Tag
+	}
+	if c.Tag == "" {
+		return "@" + c.Digest
+	}
+	return c.Tag + "@" + c.Digest
+}
+
 type Logger interface {
 	Error(format string, a ...interface{})
 	Warn(format string, a ...interface{})
@@ -125,33 +280,70 @@ func (l *DefaultLogger) Debug(format string, a ...interface{}) {
 	color.White(format, a...)
 }
 
-func (c *Image) Logging(wc *WarnCondition, logger Logger) {
-	if logger == nil {
-		logger = &DefaultLogger{}
+func (c *Image) Evaluate(p *Policy) *Result {
+	r := &Result{
+		Type:        TypeImage,
+		Name:        c.RawString,
+		Ecosystem:   EcosystemImage,
+		Version:     c.version(),
+		Tags:        c.LiveTags,
+		NewestTag:   c.NewestTag,
+		NewestPatch: c.NewestPatch,
+		EOL:         c.EOL,
+		EOLDate:     c.EOLDate,
+		Line:        c.Line,
+		LastUpdate:  c.LastUpdate,
 	}
 
 	if c.Err != nil {
-		if strings.Contains(c.Err.Error(), "unsupported registry") {
-			logger.Debug("├ INFO: %v %v\n", c.RawString, c.Err)
+		if errors.Is(c.Err, ErrSkip) {
+			r.Add("", VerdictInfo, "%v %v", c.RawString, c.Err)
 		} else {
-			logger.Error("├ ERROR: %v %v\n", c.RawString, c.Err)
+			r.Add("", VerdictError, "%v %v", c.RawString, c.Err)
 		}
-		return
+		return r
 	}
-	if c.LastUpdate.AddDate(0, 0, wc.RecentDays).Before(time.Now()) {
-		logger.Warn("├ WARN: %v last update isn't recent (%v)\n", c.RawString, c.LastUpdate.Format("2006-01-02"))
-		return
+	if p.Enabled(RuleDigestDrift) && c.Digest != "" {
+		if c.Tag != "" && !slices.Contains(c.LiveTags, c.Tag) {
+			r.Add(RuleDigestDrift, p.Severity(RuleDigestDrift), "%v tag %v has moved on from the pinned digest", c.RawString, c.Tag)
+		} else if c.Tag == "" && len(c.LiveTags) == 0 {
+			r.Add(RuleDigestDrift, p.Severity(RuleDigestDrift), "%v pinned digest no longer matches any live tag", c.RawString)
+		}
 	}
+	if p.Enabled(RuleImageEOL) && c.EOL {
+		r.Add(RuleImageEOL, p.Severity(RuleImageEOL), "%v is built on %v which is EOL", c.RawString, c.EOLCycle)
+	} else if p.Enabled(RuleImageEOLSoon) && !c.EOLDate.IsZero() && time.Now().AddDate(0, 0, p.EOLSoonDays).After(c.EOLDate) {
+		r.Add(RuleImageEOLSoon, p.Severity(RuleImageEOLSoon), "%v is built on %v which EOL is recent (%v)", c.RawString, c.EOLCycle, c.EOLDate.Format("2006-01-02"))
+	}
+	if p.Enabled(RuleStaleImage) && c.LastUpdate.AddDate(0, 0, p.StaleDaysFor(EcosystemImage)).Before(time.Now()) {
+		r.Add(RuleStaleImage, p.Severity(RuleStaleImage), "%v last update isn't recent (%v)%v", c.RawString, c.LastUpdate.Format("2006-01-02"), c.suggestion())
+		return r
+	}
+	return r
+}
+
+func (c *Image) Logging(p *Policy, logger Logger) {
+	c.Evaluate(p).Logging(logger)
 }
 
 func (c *Image) LoadCache() bool {
-	v, ok := imageCache.Load(c.RawString)
+	if errors.Is(c.Err, ErrSkip) {
+		return true
+	}
+	v, ok := imageCache.Load(c.String())
 	if ok {
 		_v := v.(*Image)
 		c.Repository = _v.Repository
 		c.Namespace = _v.Namespace
 		c.Registry = _v.Registry
 		c.Tag = _v.Tag
+		c.Digest = _v.Digest
+		c.LiveTags = _v.LiveTags
+		c.NewestTag = _v.NewestTag
+		c.NewestPatch = _v.NewestPatch
+		c.EOLCycle = _v.EOLCycle
+		c.EOL = _v.EOL
+		c.EOLDate = _v.EOLDate
 		c.Err = _v.Err
 		c.LastUpdate = _v.LastUpdate
 	}
@@ -159,26 +351,87 @@ func (c *Image) LoadCache() bool {
 }
 
 func (c *Image) StoreCache() {
-	imageCache.Store(c.RawString, c)
+	if errors.Is(c.Err, ErrSkip) {
+		return
+	}
+	imageCache.Store(c.String(), c)
 }
 
-func (c *Image) SyncWithRegistry(ctx context.Context, cli *http.Client) *Image {
+// SyncWithRegistry reads the last update of the image, authenticating with the credential docker has for the registry.
+func (c *Image) SyncWithRegistry(ctx context.Context, cli *http.Client, creds *dockerconfig.Config) *Image {
 	if c.Err != nil {
 		return c
 	}
+	cred, err := creds.Credential(c.Registry)
+	if err != nil {
+		c.Err = err
+		return c
+	}
 
 	handler, ok := registryHandlers[c.Registry]
 	if !ok {
-		c.Err = fmt.Errorf("unsupported registry: %s", c.Registry)
-		return c
+		handler = &ociHandler{registry: c.Registry}
 	}
 
-	lastUpdate, err := handler.ReadTag(ctx, cli, c.Namespace, c.Repository, c.Tag)
+	if c.Digest != "" {
+		return c.syncDigest(ctx, cli, cred, handler)
+	}
+	lastUpdate, err := handler.ReadTag(ctx, cli, cred, c.Namespace, c.Repository, c.Tag)
 	if err != nil {
 		c.Err = err
 		return c
 	}
 	c.LastUpdate = lastUpdate
 
+	// suggestions are best effort, a registry which doesn't list tags only leaves them out
+	if tags, err := handler.ListTags(ctx, cli, cred, c.Namespace, c.Repository); err == nil {
+		c.NewestTag, c.NewestPatch = suggestTags(c.Tag, tags)
+	}
+
+	return c
+}
+
+// syncDigest resolves the pinned digest to the tags pointing at it. With a tag given too, only that tag is checked.
+// When the registry doesn't tell the push time, the creation time of the image config stands in for it.
+func (c *Image) syncDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, handler RegistryHandler) *Image {
+	var tags []string
+	if c.Tag != "" {
+		tags = []string{c.Tag}
+	}
+	pushed, live, err := handler.ReadDigest(ctx, cli, cred, c.Namespace, c.Repository, c.Digest, tags)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	if pushed.IsZero() {
+		r, err := oci.ReadTag(ctx, cli, distributionHost(c.Registry), path.Join(c.Namespace, c.Repository), "@"+c.Digest, cred)
+		if err != nil {
+			c.Err = err
+			return c
+		}
+		pushed = r.Created
+	}
+	c.LastUpdate = pushed
+	c.LiveTags = live
+	return c
+}
+
+// SyncWithEndOfLife looks up the releases an official image is built on, and keeps the one which reaches end of life first.
+// Tags which don't map to a release, or releases unknown to endoflife.date, are left without EOL.
+func (c *Image) SyncWithEndOfLife(ctx context.Context, cli *http.Client) *Image {
+	if c.Err != nil || c.Registry != DockerHubRegistry || c.Namespace != DockerHubOfficialNamespace {
+		return c
+	}
+	for _, cycle := range imageEOLCycles(c.Repository, c.Tag) {
+		cd, err := eol.SingleCycleDetail(ctx, cli, cycle.product, cycle.cycle)
+		if err != nil || !cd.EOL && cd.EOLDate.IsZero() {
+			continue
+		}
+		if c.EOLCycle == "" || cd.EOL && !c.EOL || cd.EOL == c.EOL && cd.EOLDate.Before(c.EOLDate) {
+			c.EOLCycle = cycle.String()
+			c.EOL = cd.EOL
+			c.EOLDate = cd.EOLDate
+		}
+	}
 	return c
 }
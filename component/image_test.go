@@ -0,0 +1,205 @@
+package component
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+	"gotest.tools/v3/assert"
+)
+
+func Test_ImageFromRawString(t *testing.T) {
+	const digest = "sha256:9b0f2b1b1e5e7d4b8e3b7e9b6d2e4f0a1c3e5d7f9a1b3c5d7e9f1a3b5c7d9e1f"
+	for _, tt := range []struct {
+		raw        string
+		registry   string
+		namespace  string
+		repository string
+		tag        string
+		digest     string
+		canonical  string
+		err        string
+	}{
+		{raw: "alpine", registry: "docker.io", namespace: "library", repository: "alpine", tag: "latest", canonical: "docker.io/library/alpine:latest"},
+		{raw: "golang:1.21.1-bullseye", registry: "docker.io", namespace: "library", repository: "golang", tag: "1.21.1-bullseye", canonical: "docker.io/library/golang:1.21.1-bullseye"},
+		{raw: "bitnami/redis:7.2", registry: "docker.io", namespace: "bitnami", repository: "redis", tag: "7.2", canonical: "docker.io/bitnami/redis:7.2"},
+		{raw: "index.docker.io/nginx", registry: "docker.io", namespace: "library", repository: "nginx", tag: "latest", canonical: "docker.io/library/nginx:latest"},
+		{raw: "docker.io/library/nginx:1.25", registry: "docker.io", namespace: "library", repository: "nginx", tag: "1.25", canonical: "docker.io/library/nginx:1.25"},
+		{raw: "gcr.io/distroless/base-nossl-debian11", registry: "gcr.io", namespace: "distroless", repository: "base-nossl-debian11", tag: "latest", canonical: "gcr.io/distroless/base-nossl-debian11:latest"},
+		{raw: "registry.example.com:5000/team/app:tag", registry: "registry.example.com:5000", namespace: "team", repository: "app", tag: "tag", canonical: "registry.example.com:5000/team/app:tag"},
+		{raw: "registry.example.com:5000/team/app", registry: "registry.example.com:5000", namespace: "team", repository: "app", tag: "latest", canonical: "registry.example.com:5000/team/app:latest"},
+		{raw: "localhost/app", registry: "localhost", repository: "app", tag: "latest", canonical: "localhost/app:latest"},
+		{raw: "localhost:5000/app:1", registry: "localhost:5000", repository: "app", tag: "1", canonical: "localhost:5000/app:1"},
+		{raw: "[::1]:5000/app", registry: "[::1]:5000", repository: "app", tag: "latest", canonical: "[::1]:5000/app:latest"},
+		{raw: "Registry/app", registry: "Registry", repository: "app", tag: "latest", canonical: "Registry/app:latest"},
+		{raw: "registry.k8s.io/pause:3.9", registry: "registry.k8s.io", repository: "pause", tag: "3.9", canonical: "registry.k8s.io/pause:3.9"},
+		{raw: "ghcr.io/org/sub/repo:v1", registry: "ghcr.io", namespace: "org/sub", repository: "repo", tag: "v1", canonical: "ghcr.io/org/sub/repo:v1"},
+		{raw: "public.ecr.aws/docker/library/alpine:3.19", registry: "public.ecr.aws", namespace: "docker/library", repository: "alpine", tag: "3.19", canonical: "public.ecr.aws/docker/library/alpine:3.19"},
+		{raw: "alpine@" + digest, registry: "docker.io", namespace: "library", repository: "alpine", digest: digest, canonical: "docker.io/library/alpine@" + digest},
+		{raw: "alpine:3.19@" + digest, registry: "docker.io", namespace: "library", repository: "alpine", tag: "3.19", digest: digest, canonical: "docker.io/library/alpine:3.19@" + digest},
+		{raw: "quay.io/a_b/c-d.e__f:1.0-rc.1", registry: "quay.io", namespace: "a_b", repository: "c-d.e__f", tag: "1.0-rc.1", canonical: "quay.io/a_b/c-d.e__f:1.0-rc.1"},
+		{raw: "Alpine", err: "lowercase"},
+		{raw: "docker.io/Library/alpine", err: "lowercase"},
+		{raw: "alpine:", err: "invalid tag"},
+		{raw: "alpine:-1", err: "invalid tag"},
+		{raw: "alpine@sha256:short", err: "invalid digest"},
+		{raw: "alpine//edge", err: "invalid path component"},
+		{raw: "-registry.io/app", err: "invalid domain"},
+		{raw: ":tag", err: "empty"},
+	} {
+		t.Run(tt.raw, func(t *testing.T) {
+			c := (&Image{}).FromRawString(tt.raw)
+			if tt.err != "" {
+				assert.ErrorContains(t, c.Err, tt.err)
+				return
+			}
+			assert.NilError(t, c.Err)
+			assert.Equal(t, c.Registry, tt.registry)
+			assert.Equal(t, c.Namespace, tt.namespace)
+			assert.Equal(t, c.Repository, tt.repository)
+			assert.Equal(t, c.Tag, tt.tag)
+			assert.Equal(t, c.Digest, tt.digest)
+			assert.Equal(t, c.String(), tt.canonical)
+		})
+	}
+}
+
+type fakeRegistry struct {
+	pushed time.Time
+	live   map[string][]string
+	tags   []string
+}
+
+func (h *fakeRegistry) ListTags(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository string) ([]string, error) {
+	return h.tags, nil
+}
+
+func (h *fakeRegistry) ReadTag(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, tag string) (time.Time, error) {
+	return h.pushed, nil
+}
+
+func (h *fakeRegistry) ReadDigest(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential, namespace, repository, digest string, tags []string) (time.Time, []string, error) {
+	var live []string
+	for _, t := range h.live[digest] {
+		if len(tags) == 0 || slices.Contains(tags, t) {
+			live = append(live, t)
+		}
+	}
+	return h.pushed, live, nil
+}
+
+func Test_ImageDigestDrift(t *testing.T) {
+	const (
+		current = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		old     = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	h := &fakeRegistry{pushed: time.Now(), live: map[string][]string{current: {"3.19", "3.19.1"}}}
+	for _, tt := range []struct {
+		raw     string
+		tags    []string
+		drifted bool
+	}{
+		{raw: "alpine@" + current, tags: []string{"3.19", "3.19.1"}},
+		{raw: "alpine:3.19@" + current, tags: []string{"3.19"}},
+		{raw: "alpine@" + old, drifted: true},
+		{raw: "alpine:3.19@" + old, drifted: true},
+	} {
+		t.Run(tt.raw, func(t *testing.T) {
+			c := (&Image{}).FromRawString(tt.raw)
+			c = c.syncDigest(context.Background(), nil, nil, h)
+			assert.NilError(t, c.Err)
+			r := c.Evaluate(NewPolicy())
+			assert.DeepEqual(t, r.Tags, tt.tags)
+			assert.Equal(t, len(r.Findings) == 1 && r.Findings[0].Rule == RuleDigestDrift, tt.drifted)
+		})
+	}
+}
+
+func Test_SuggestTags(t *testing.T) {
+	tags := []string{
+		"latest", "edge", "3", "3.13", "3.13.0", "3.13.5", "3.13.12", "3.19", "3.19.1", "3.20", "3.20.3", "3.21.0-rc1", "20240606",
+		"1.21.1-bullseye", "1.21.13-bullseye", "1.23.2-bullseye", "1.23.2-bookworm", "1.23.2-alpine3.20", "1.21.1-alpine3.18", "1.21.13-alpine3.20",
+		"v2.1", "v2.4", "2.9",
+	}
+	for _, tt := range []struct {
+		current string
+		newest  string
+		patch   string
+	}{
+		{current: "3.13", newest: "3.20", patch: "3.13.12"},
+		{current: "3.13.5", newest: "3.20.3", patch: "3.13.12"},
+		{current: "3", newest: ""},
+		{current: "3.20.3", newest: "", patch: ""},
+		{current: "1.21.1-bullseye", newest: "1.23.2-bullseye", patch: "1.21.13-bullseye"},
+		{current: "1.21.1-alpine3.18", newest: "1.23.2-alpine3.20", patch: "1.21.13-alpine3.20"},
+		{current: "v2.1", newest: "v2.4"},
+		{current: "latest"},
+	} {
+		t.Run(tt.current, func(t *testing.T) {
+			newest, patch := suggestTags(tt.current, tags)
+			assert.Equal(t, newest, tt.newest)
+			assert.Equal(t, patch, tt.patch)
+		})
+	}
+}
+
+func Test_ImageStaleSuggestion(t *testing.T) {
+	registryHandlers["fake.io"] = &fakeRegistry{pushed: time.Now().AddDate(-3, 0, 0), tags: []string{"3.13", "3.13.12", "3.20"}}
+	defer delete(registryHandlers, "fake.io")
+
+	c := (&Image{}).FromRawString("fake.io/library/alpine:3.13")
+	c = c.SyncWithRegistry(context.Background(), nil, nil)
+	r := c.Evaluate(NewPolicy())
+	assert.Equal(t, r.NewestTag, "3.20")
+	assert.Equal(t, r.NewestPatch, "3.13.12")
+	assert.Assert(t, strings.HasSuffix(r.Reason, ", newest 3.20, newest patch 3.13.12"), r.Reason)
+}
+
+func Test_ImageEOLCycles(t *testing.T) {
+	for _, tt := range []struct {
+		repository string
+		tag        string
+		cycles     []string
+	}{
+		{repository: "debian", tag: "buster", cycles: []string{"debian 10"}},
+		{repository: "debian", tag: "bookworm-slim", cycles: []string{"debian 12"}},
+		{repository: "debian", tag: "10.13", cycles: []string{"debian 10"}},
+		{repository: "ubuntu", tag: "18.04", cycles: []string{"ubuntu 18.04"}},
+		{repository: "ubuntu", tag: "jammy-20240101", cycles: []string{"ubuntu 22.04"}},
+		{repository: "alpine", tag: "3.13", cycles: []string{"alpine 3.13"}},
+		{repository: "alpine", tag: "3.19.1", cycles: []string{"alpine 3.19"}},
+		{repository: "centos", tag: "7", cycles: []string{"centos 7"}},
+		{repository: "centos", tag: "centos8", cycles: []string{"centos 8"}},
+		{repository: "centos", tag: "stream9", cycles: []string{"centos-stream 9"}},
+		{repository: "node", tag: "18.19.0-alpine3.19", cycles: []string{"nodejs 18", "alpine 3.19"}},
+		{repository: "python", tag: "3.8-slim-buster", cycles: []string{"python 3.8", "debian 10"}},
+		{repository: "golang", tag: "1.21.1-bullseye", cycles: []string{"go 1.21", "debian 11"}},
+		{repository: "postgres", tag: "9.6-alpine", cycles: []string{"postgresql 9.6"}},
+		{repository: "postgres", tag: "16.2", cycles: []string{"postgresql 16"}},
+		{repository: "redis", tag: "6", cycles: nil},
+		{repository: "nginx", tag: "latest", cycles: nil},
+		{repository: "busybox", tag: "1.36", cycles: nil},
+	} {
+		t.Run(tt.repository+":"+tt.tag, func(t *testing.T) {
+			var cycles []string
+			for _, c := range imageEOLCycles(tt.repository, tt.tag) {
+				cycles = append(cycles, c.String())
+			}
+			assert.DeepEqual(t, cycles, tt.cycles)
+		})
+	}
+}
+
+func Test_ImageEOLEvaluate(t *testing.T) {
+	c := (&Image{}).FromRawString("debian:buster")
+	c.LastUpdate = time.Now()
+	c.EOLCycle, c.EOL, c.EOLDate = "debian 10", true, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	r := c.Evaluate(NewPolicy())
+	assert.Equal(t, r.Verdict, VerdictWarn)
+	assert.Equal(t, r.Findings[0].Rule, RuleImageEOL)
+	assert.Equal(t, r.Findings[0].Message, "debian:buster is built on debian 10 which is EOL")
+}
@@ -0,0 +1,123 @@
+package component
+
+import (
+	"strconv"
+	"strings"
+)
+
+// eolCycle is a release cycle of a product on endoflife.date.
+type eolCycle struct {
+	product string
+	cycle   string
+}
+
+func (c eolCycle) String() string {
+	return c.product + " " + c.cycle
+}
+
+// imageEOLProduct maps an official image to its endoflife.date product.
+// depth is how many components of the version name a cycle, e.g. 1 for nodejs 20 and 2 for python 3.12.
+type imageEOLProduct struct {
+	product string
+	depth   int
+}
+
+var imageEOLProducts = map[string]imageEOLProduct{
+	"alpine":          {product: "alpine", depth: 2},
+	"centos":          {product: "centos", depth: 1},
+	"debian":          {product: "debian", depth: 1},
+	"eclipse-temurin": {product: "eclipse-temurin", depth: 1},
+	"golang":          {product: "go", depth: 2},
+	"mariadb":         {product: "mariadb", depth: 2},
+	"mongo":           {product: "mongodb", depth: 2},
+	"mysql":           {product: "mysql", depth: 2},
+	"nginx":           {product: "nginx", depth: 2},
+	"node":            {product: "nodejs", depth: 1},
+	"php":             {product: "php", depth: 2},
+	"postgres":        {product: "postgresql", depth: 1},
+	"python":          {product: "python", depth: 2},
+	"rabbitmq":        {product: "rabbitmq", depth: 2},
+	"redis":           {product: "redis", depth: 2},
+	"ruby":            {product: "ruby", depth: 2},
+	"ubuntu":          {product: "ubuntu", depth: 2},
+}
+
+var debianCodenames = map[string]string{
+	"jessie":   "8",
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+}
+
+var ubuntuCodenames = map[string]string{
+	"trusty":   "14.04",
+	"xenial":   "16.04",
+	"bionic":   "18.04",
+	"focal":    "20.04",
+	"jammy":    "22.04",
+	"kinetic":  "22.10",
+	"lunar":    "23.04",
+	"mantic":   "23.10",
+	"noble":    "24.04",
+	"oracular": "24.10",
+}
+
+// imageEOLCycles returns the cycles an official image tag is built on: the product of the image itself,
+// and the OS named by the variant, e.g. nodejs 18 and debian 10 for node:18.19-buster.
+func imageEOLCycles(repository, tag string) (cs []eolCycle) {
+	p, ok := imageEOLProducts[repository]
+	if !ok {
+		return
+	}
+
+	words := strings.Split(tag, "-")
+	if c, ok := osCycle(words[0]); ok && (c.product == p.product || p.product == "centos" && c.product == "centos-stream") {
+		// debian:bookworm-slim, ubuntu:jammy-20240101, centos:stream9
+		cs = append(cs, c)
+	} else if t, ok := parseImageTag(tag); ok {
+		version := t.version
+		// postgres cycles were major.minor before 10
+		if p.product == "postgresql" && version[0] < 10 {
+			p.depth = 2
+		}
+		if len(version) >= p.depth {
+			cycle := make([]string, p.depth)
+			for i := range cycle {
+				cycle[i] = strconv.Itoa(version[i])
+			}
+			if p.product == "ubuntu" && len(cycle[1]) == 1 {
+				cycle[1] = "0" + cycle[1]
+			}
+			cs = append(cs, eolCycle{product: p.product, cycle: strings.Join(cycle, ".")})
+		}
+	}
+
+	for _, w := range words[1:] {
+		if c, ok := osCycle(w); ok && c.product != p.product {
+			cs = append(cs, c)
+		}
+	}
+	return
+}
+
+// osCycle recognizes an OS release in a tag word like buster, jammy, alpine3.19 or stream9.
+func osCycle(w string) (eolCycle, bool) {
+	if v, ok := debianCodenames[w]; ok {
+		return eolCycle{product: "debian", cycle: v}, true
+	}
+	if v, ok := ubuntuCodenames[w]; ok {
+		return eolCycle{product: "ubuntu", cycle: v}, true
+	}
+	if v, ok := strings.CutPrefix(w, "alpine"); ok && v != "" {
+		return eolCycle{product: "alpine", cycle: v}, true
+	}
+	if v, ok := strings.CutPrefix(w, "stream"); ok && v != "" {
+		return eolCycle{product: "centos-stream", cycle: v}, true
+	}
+	if v, ok := strings.CutPrefix(w, "centos"); ok && v != "" {
+		return eolCycle{product: "centos", cycle: v}, true
+	}
+	return eolCycle{}, false
+}
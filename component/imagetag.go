@@ -0,0 +1,101 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	imageTagPattern    = regexp.MustCompile(`^([a-zA-Z]*)(\d+(?:\.\d+)*)(?:[-_](.+))?$`)
+	imageTagNumberPart = regexp.MustCompile(`\d+`)
+)
+
+// imageTag is a version-like tag such as 1.21.1-bullseye or v2.3-alpine3.19.
+type imageTag struct {
+	raw     string
+	prefix  string
+	version []int
+	variant string
+}
+
+func parseImageTag(s string) (*imageTag, bool) {
+	m := imageTagPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	t := &imageTag{raw: s, prefix: m[1], variant: m[3]}
+	for _, p := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		t.version = append(t.version, n)
+	}
+	return t, true
+}
+
+// line identifies tags which are interchangeable upgrades, e.g. 1.21-alpine3.18 and 1.22-alpine3.19.
+// Numbers in the variant are versions of the base, so they are left out.
+// Date tags like 20240606 are kept apart from versions with the same number of components.
+func (t *imageTag) line() string {
+	return fmt.Sprintf("%v|%v|%v|%v", t.prefix, len(t.version), t.version[0] >= 1000, t.variantLine())
+}
+
+func (t *imageTag) variantLine() string {
+	return imageTagNumberPart.ReplaceAllString(t.variant, "")
+}
+
+// compare orders by version, then by the versions in the variant.
+func (t *imageTag) compare(u *imageTag) int {
+	if c := slices.Compare(t.version, u.version); c != 0 {
+		return c
+	}
+	return slices.Compare(variantNumbers(t.variant), variantNumbers(u.variant))
+}
+
+func variantNumbers(s string) []int {
+	var ns []int
+	for _, p := range imageTagNumberPart.FindAllString(s, -1) {
+		n, _ := strconv.Atoi(p)
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+// suggestTags returns the newest tag in the line of current, and the newest patch release of its minor version.
+// Either is empty when current is already the newest.
+func suggestTags(current string, tags []string) (newest, patch string) {
+	cur, ok := parseImageTag(current)
+	if !ok {
+		return
+	}
+	var best, bestPatch *imageTag
+	for _, s := range tags {
+		t, ok := parseImageTag(s)
+		if !ok {
+			continue
+		}
+		if t.line() == cur.line() && t.compare(cur) > 0 && (best == nil || t.compare(best) > 0) {
+			best = t
+		}
+		// 3.13 and 3.13.1 are both in the minor line of 3.13
+		if len(cur.version) >= 2 && len(t.version) >= 3 &&
+			t.prefix == cur.prefix &&
+			t.variantLine() == cur.variantLine() &&
+			slices.Equal(t.version[:2], cur.version[:2]) &&
+			t.compare(cur) > 0 &&
+			(bestPatch == nil || t.compare(bestPatch) > 0) {
+			bestPatch = t
+		}
+	}
+	if best != nil {
+		newest = best.raw
+	}
+	if bestPatch != nil {
+		patch = bestPatch.raw
+	}
+	return
+}
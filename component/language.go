@@ -2,6 +2,7 @@ package component
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"sync"
@@ -12,22 +13,64 @@ import (
 
 var languageCache = sync.Map{}
 
+// cycleDepths is the number of version components of a release cycle per product, 2 (major.minor) by default.
+var cycleDepths = map[string]int{
+	"nodejs": 1,
+	"java":   1,
+}
+
+// eolProducts maps languages to the endoflife.date products tracking them, the name itself by default.
+var eolProducts = map[string]string{
+	"java": "eclipse-temurin",
+}
+
 type Language struct {
 	Name               string
 	Version            string
 	EOL                bool
 	EOLDate            time.Time
 	LatestPatchVersion string
+	Line               int
 	Err                error
 }
 
+// IsLatestPatch reports whether Version is the latest patch of its cycle.
+// Versions without a patch, like 3.12 of .python-version, float to the latest and count as latest.
 func (t *Language) IsLatestPatch() bool {
+	if t.LatestPatchVersion == "" || len(strings.Split(t.Version, ".")) < len(strings.Split(t.LatestPatchVersion, ".")) {
+		return true
+	}
 	return t.Version == t.LatestPatchVersion
 }
 
-func (t *Language) SyncWithEndOfLife(ctx context.Context, cli *http.Client) *Language {
+// CycleDepth returns the number of version components of a release cycle of a language.
+func CycleDepth(name string) int {
+	if depth, ok := cycleDepths[name]; ok {
+		return depth
+	}
+	return 2
+}
+
+func (t *Language) cycle() (string, error) {
+	depth := CycleDepth(t.Name)
 	splited := strings.Split(t.Version, ".")
-	cd, err := eol.SingleCycleDetail(ctx, cli, t.Name, strings.Join(splited[0:2], "."))
+	if len(splited) < depth {
+		return "", fmt.Errorf("version %v has no release cycle", t.Version)
+	}
+	return strings.Join(splited[:depth], "."), nil
+}
+
+func (t *Language) SyncWithEndOfLife(ctx context.Context, cli *http.Client) *Language {
+	cycle, err := t.cycle()
+	if err != nil {
+		t.Err = err
+		return t
+	}
+	product, ok := eolProducts[t.Name]
+	if !ok {
+		product = t.Name
+	}
+	cd, err := eol.SingleCycleDetail(ctx, cli, product, cycle)
 	if err != nil {
 		t.Err = err
 		return t
@@ -40,44 +83,53 @@ func (t *Language) SyncWithEndOfLife(ctx context.Context, cli *http.Client) *Lan
 	return t
 }
 
-func (t *Language) Logging(wc *WarnCondition, logger Logger) {
-	if logger == nil {
-		logger = &DefaultLogger{}
+func (t *Language) Evaluate(p *Policy) *Result {
+	r := &Result{
+		Type:    TypeLanguage,
+		Name:    t.Name,
+		Version: t.Version,
+		Line:    t.Line,
+		EOLDate: t.EOLDate,
+		EOL:     t.EOL,
 	}
 
 	if t.Err != nil {
-		logger.Error("├ ERROR: %v %v\n", t.Name, t.Err)
-		return
+		r.Add("", VerdictError, "%v %v", t.Name, t.Err)
+		return r
 	}
 
-	if !t.IsLatestPatch() {
-		logger.Warn("├ WARN: %v@%v is not latest patch (%v)\n", t.Name, t.Version, t.LatestPatchVersion)
+	if p.Enabled(RuleNotLatestPatch) && !t.IsLatestPatch() {
+		r.Add(RuleNotLatestPatch, p.Severity(RuleNotLatestPatch), "%v@%v is not latest patch (%v)", t.Name, t.Version, t.LatestPatchVersion)
 	}
 
-	if wc.IfArchived && t.EOL {
-		logger.Warn("├ WARN: %v%v is EOL\n", t.Name, t.Version)
-		return
+	if p.Enabled(RuleLanguageEOL) && t.EOL {
+		r.Add(RuleLanguageEOL, p.Severity(RuleLanguageEOL), "%v%v is EOL", t.Name, t.Version)
+		return r
 	}
 
-	if !t.EOLDate.IsZero() && time.Now().AddDate(0, 0, wc.RecentDays).After(t.EOLDate) {
-		logger.Warn("├ WARN: %v@%v EOL is recent (%v)\n", t.Name, t.Version, t.EOLDate.Format("2006-01-02"))
-		return
+	if p.Enabled(RuleLanguageEOLSoon) && !t.EOLDate.IsZero() && time.Now().AddDate(0, 0, p.EOLSoonDays).After(t.EOLDate) {
+		r.Add(RuleLanguageEOLSoon, p.Severity(RuleLanguageEOLSoon), "%v@%v EOL is recent (%v)", t.Name, t.Version, t.EOLDate.Format("2006-01-02"))
+		return r
 	}
+	return r
+}
+
+func (t *Language) Logging(p *Policy, logger Logger) {
+	t.Evaluate(p).Logging(logger)
 }
 
 func (t *Language) LoadCache() bool {
-	v, ok := languageCache.Load(t.Name)
+	v, ok := languageCache.Load(t.Name + "@" + t.Version)
 	if ok {
 		_v := v.(*Language)
-		t.Name = _v.Name
-		t.Version = _v.Version
 		t.EOL = _v.EOL
 		t.EOLDate = _v.EOLDate
+		t.LatestPatchVersion = _v.LatestPatchVersion
 		t.Err = _v.Err
 	}
 	return ok
 }
 
 func (t *Language) StoreCache() {
-	languageCache.Store(t.Name+t.Version, t)
+	languageCache.Store(t.Name+"@"+t.Version, t)
 }
@@ -0,0 +1,35 @@
+package component
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_LanguageCycle(t *testing.T) {
+	for _, tt := range []struct {
+		name, version, cycle string
+		err                  bool
+	}{
+		{name: "nodejs", version: "20.11.0", cycle: "20"},
+		{name: "nodejs", version: "18", cycle: "18"},
+		{name: "python", version: "3.12.1", cycle: "3.12"},
+		{name: "go", version: "1.22", cycle: "1.22"},
+		{name: "python", version: "3", err: true},
+	} {
+		l := &Language{Name: tt.name, Version: tt.version}
+		cycle, err := l.cycle()
+		if tt.err {
+			assert.Assert(t, err != nil)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, cycle, tt.cycle)
+	}
+}
+
+func Test_LanguageIsLatestPatch(t *testing.T) {
+	assert.Assert(t, (&Language{Version: "3.12.1", LatestPatchVersion: "3.12.1"}).IsLatestPatch())
+	assert.Assert(t, !(&Language{Version: "3.12.0", LatestPatchVersion: "3.12.1"}).IsLatestPatch())
+	assert.Assert(t, (&Language{Version: "3.12", LatestPatchVersion: "3.12.1"}).IsLatestPatch())
+}
@@ -2,32 +2,73 @@ package component
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/sdk/cratesio"
 	"github.com/izziiyt/compaa/sdk/gopkg"
+	"github.com/izziiyt/compaa/sdk/maven"
 	"github.com/izziiyt/compaa/sdk/npm"
+	"github.com/izziiyt/compaa/sdk/nuget"
+	"github.com/izziiyt/compaa/sdk/packagist"
 	"github.com/izziiyt/compaa/sdk/pypi"
 	"github.com/izziiyt/compaa/sdk/rubygem"
 )
 
 var moduleCache = sync.Map{}
 
+// cacheKey tells apart modules of the same name in different ecosystems, like requests of npm and of PyPI,
+// and modules whose repository is preset by their source, like a git dependency on a fork.
+func (t *Module) cacheKey() string {
+	return t.Ecosystem + "\x00" + t.Name + "\x00" + t.GHOrg + "/" + t.GHRepo
+}
+
+var (
+	// GitHubRepositoryRegexp captures the org and the repository of urls like https://github.com/org/repo.git,
+	// scm:git:git@github.com:org/repo.git or git+https://github.com/org/repo?branch=main#0123abc
+	GitHubRepositoryRegexp = regexp.MustCompile(`github\.com[/:]([\w.-]+)/([\w.-]+?)(?:\.git)?(?:[/#?].*)?$`)
+	// a plain maven version, not a property reference like ${spring.version} or a range like [1.0,2.0)
+	mavenVersionRegexp = regexp.MustCompile(`^[\w.-]+$`)
+	// a plain nuget version, not a range like [1.0,2.0) or a floating version like 6.*
+	nugetVersionRegexp = regexp.MustCompile(`^[\w.+-]+$`)
+	// a full commit SHA, the only ref of an action which can't be moved
+	commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// ErrSkip marks a component which is deliberately not checked. It is reported as info.
+var ErrSkip = errors.New("skipped")
+
 type Module struct {
-	Name     string
-	Archived bool
-	LastPush time.Time
-	GHOrg    string
-	GHRepo   string
+	Name      string
+	Version   string
+	Ecosystem string
+	Archived  bool
+	LastPush  time.Time
+	GHOrg     string
+	GHRepo    string
+	Line      int
+	// Indirect is true for transitive dependencies, Path leads from a direct dependency to the module.
+	Indirect bool
+	Path     []string
 	Err      error
+
+	// key is the cache key taken by LoadCache, before syncing fills in the repository
+	key string
 }
 
 func (t *Module) LoadCache() bool {
-	v, ok := moduleCache.Load(t.Name)
+	// skips and errors found while parsing are kept, there is nothing to sync
+	if t.Err != nil {
+		return true
+	}
+	t.key = t.cacheKey()
+	v, ok := moduleCache.Load(t.key)
 	if ok {
 		_v := v.(*Module)
 		t.Name = _v.Name
@@ -41,7 +82,10 @@ func (t *Module) LoadCache() bool {
 }
 
 func (t *Module) StoreCache() {
-	moduleCache.Store(t.Name, t)
+	if t.key == "" || errors.Is(t.Err, ErrSkip) {
+		return
+	}
+	moduleCache.Store(t.key, t)
 }
 
 func (m *Module) SyncWithNPM(ctx context.Context, cli *http.Client) *Module {
@@ -149,25 +193,169 @@ func (t *Module) SyncWithRubyGem(ctx context.Context, cli *http.Client) *Module
 	return t
 }
 
-func (t *Module) Logging(wc *WarnCondition, logger Logger) {
-	if logger == nil {
-		logger = &DefaultLogger{}
+func (t *Module) SyncWithCratesIO(ctx context.Context, cli *http.Client) *Module {
+	if t.Err != nil {
+		return t
+	}
+	r, err := cratesio.GetCrate(ctx, cli, t.Name)
+	if err != nil {
+		t.Err = err
+		return t
+	}
+	for _, uri := range []string{r.Crate.Repository, r.Crate.Homepage, r.Crate.Documentation} {
+		if m := GitHubRepositoryRegexp.FindStringSubmatch(uri); m != nil {
+			t.GHOrg = m[1]
+			t.GHRepo = m[2]
+			return t
+		}
+	}
+	t.Err = fmt.Errorf("github url not found in crates.io %v", t.Name)
+	return t
+}
+
+// SyncWithMaven finds the repository in the scm of the POM on Maven Central. Parent POMs are followed, as they often declare it.
+// Name is "groupId:artifactId".
+func (t *Module) SyncWithMaven(ctx context.Context, cli *http.Client) *Module {
+	if t.Err != nil {
+		return t
+	}
+	groupID, artifactID, ok := strings.Cut(t.Name, ":")
+	if !ok {
+		t.Err = fmt.Errorf("unexpected maven artifact %v", t.Name)
+		return t
+	}
+	version := t.Version
+	if !mavenVersionRegexp.MatchString(version) {
+		// versions managed elsewhere or unresolved are looked up at the latest release
+		v, err := maven.LatestVersion(ctx, cli, groupID, artifactID)
+		if err != nil {
+			t.Err = err
+			return t
+		}
+		version = v
+	}
+	for i := 0; i < 5; i++ {
+		r, err := maven.GetPOM(ctx, cli, groupID, artifactID, version)
+		if err != nil {
+			t.Err = err
+			return t
+		}
+		for _, uri := range []string{r.SCM.URL, r.SCM.Connection, r.URL} {
+			if m := GitHubRepositoryRegexp.FindStringSubmatch(uri); m != nil {
+				t.GHOrg = m[1]
+				t.GHRepo = m[2]
+				return t
+			}
+		}
+		if r.Parent == nil {
+			break
+		}
+		groupID, artifactID, version = r.Parent.GroupID, r.Parent.ArtifactID, r.Parent.Version
 	}
+	t.Err = fmt.Errorf("github url not found in maven %v", t.Name)
+	return t
+}
 
+func (t *Module) SyncWithPackagist(ctx context.Context, cli *http.Client) *Module {
 	if t.Err != nil {
-		if strings.Contains(t.Err.Error(), "unsupported registry") {
-			logger.Debug("├ INFO: %v %v\n", t.Name, t.Err)
+		return t
+	}
+	r, err := packagist.GetPackage(ctx, cli, t.Name)
+	if err != nil {
+		t.Err = err
+		return t
+	}
+	for _, uri := range []string{r.Source.URL, r.Homepage} {
+		if m := GitHubRepositoryRegexp.FindStringSubmatch(uri); m != nil {
+			t.GHOrg = m[1]
+			t.GHRepo = m[2]
+			return t
+		}
+	}
+	t.Err = fmt.Errorf("github url not found in packagist %v", t.Name)
+	return t
+}
+
+// SyncWithNuGet finds the repository in the repository or the projectUrl of the nuspec on nuget.org.
+func (t *Module) SyncWithNuGet(ctx context.Context, cli *http.Client) *Module {
+	if t.Err != nil {
+		return t
+	}
+	version := t.Version
+	if !nugetVersionRegexp.MatchString(version) {
+		// versions managed elsewhere, ranges and floating versions are looked up at the latest release
+		v, err := nuget.LatestVersion(ctx, cli, t.Name)
+		if err != nil {
+			t.Err = err
+			return t
+		}
+		version = v
+	}
+	r, err := nuget.GetNuspec(ctx, cli, t.Name, version)
+	if err != nil {
+		t.Err = err
+		return t
+	}
+	for _, uri := range []string{r.Metadata.Repository.URL, r.Metadata.ProjectURL} {
+		if m := GitHubRepositoryRegexp.FindStringSubmatch(uri); m != nil {
+			t.GHOrg = m[1]
+			t.GHRepo = m[2]
+			return t
+		}
+	}
+	t.Err = fmt.Errorf("github url not found in nuget %v", t.Name)
+	return t
+}
+
+func (t *Module) Evaluate(p *Policy) *Result {
+	r := &Result{
+		Type:      TypeModule,
+		Name:      t.Name,
+		Version:   t.Version,
+		Ecosystem: t.Ecosystem,
+		Line:      t.Line,
+		GHOrg:     t.GHOrg,
+		GHRepo:    t.GHRepo,
+		LastPush:  t.LastPush,
+		Archived:  t.Archived,
+		Indirect:  t.Indirect,
+		DepPath:   t.Path,
+	}
+
+	if t.Err != nil {
+		if errors.Is(t.Err, ErrSkip) || strings.Contains(t.Err.Error(), "unsupported registry") {
+			r.Add("", VerdictInfo, "%v %v", t.describe(), t.Err)
 		} else {
-			logger.Error("├ ERROR: %v %v\n", t.Name, t.Err)
+			r.Add("", VerdictError, "%v %v", t.describe(), t.Err)
 		}
-		return
+		return r
 	}
-	if wc.IfArchived && t.Archived {
-		logger.Warn("├ WARN: %v is archived\n", t.Name)
-		return
+	if p.Enabled(RuleUnpinnedAction) && t.Ecosystem == EcosystemActions && !commitSHARegexp.MatchString(t.Version) {
+		r.Add(RuleUnpinnedAction, p.Severity(RuleUnpinnedAction), "%v@%v is not pinned to a commit SHA", t.Name, t.Version)
 	}
-	if t.LastPush.AddDate(0, 0, wc.RecentDays).Before(time.Now()) {
-		logger.Warn("├ WARN: %v last push isn't recent (%v)\n", t.Name, t.LastPush.Format("2006-01-02"))
-		return
+	if p.Enabled(RuleArchived) && t.Archived {
+		r.Add(RuleArchived, p.Severity(RuleArchived), "%v is archived", t.describe())
+		return r
+	}
+	if p.Enabled(RuleStalePush) && t.LastPush.AddDate(0, 0, p.StaleDaysFor(t.Ecosystem)).Before(time.Now()) {
+		r.Add(RuleStalePush, p.Severity(RuleStalePush), "%v last push isn't recent (%v)", t.describe(), t.LastPush.Format("2006-01-02"))
+		return r
+	}
+	return r
+}
+
+// describe returns the name annotated with how the module is depended on.
+func (t *Module) describe() string {
+	switch {
+	case len(t.Path) > 0:
+		return fmt.Sprintf("%v (indirect via %v)", t.Name, strings.Join(t.Path, " > "))
+	case t.Indirect:
+		return t.Name + " (indirect)"
+	default:
+		return t.Name
 	}
 }
+
+func (t *Module) Logging(p *Policy, logger Logger) {
+	t.Evaluate(p).Logging(logger)
+}
@@ -0,0 +1,73 @@
+package component
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ModuleEvaluateUnpinnedAction(t *testing.T) {
+	p := NewPolicy()
+	for _, tt := range []struct {
+		version  string
+		unpinned bool
+	}{
+		{version: "v4", unpinned: true},
+		{version: "main", unpinned: true},
+		{version: "b4ffde65f46336ab88eb53be808477a3936bae11"},
+	} {
+		m := &Module{Ecosystem: EcosystemActions, Name: "actions/checkout", Version: tt.version, LastPush: time.Now()}
+		r := m.Evaluate(p)
+		assert.Equal(t, len(r.Findings) == 1 && r.Findings[0].Rule == RuleUnpinnedAction, tt.unpinned, tt.version)
+	}
+	// refs of other ecosystems are versions, not git refs
+	m := &Module{Ecosystem: EcosystemNPM, Name: "react", Version: "18.2.0", LastPush: time.Now()}
+	assert.Equal(t, len(m.Evaluate(p).Findings), 0)
+}
+
+// syncCache stands in for handler.Sync: a miss is synced by sync and stored.
+func syncCache(t *testing.T, m *Module, sync func()) bool {
+	if m.LoadCache() {
+		return true
+	}
+	sync()
+	m.StoreCache()
+	t.Cleanup(func() { moduleCache.Delete(m.key) })
+	return false
+}
+
+func Test_ModuleCacheEcosystem(t *testing.T) {
+	npm := &Module{Ecosystem: EcosystemNPM, Name: "requests"}
+	assert.Assert(t, !syncCache(t, npm, func() { npm.GHOrg, npm.GHRepo = "npm-org", "requests" }))
+
+	pypi := &Module{Ecosystem: EcosystemPyPI, Name: "requests"}
+	assert.Assert(t, !pypi.LoadCache())
+	assert.Equal(t, pypi.GHOrg, "")
+
+	again := &Module{Ecosystem: EcosystemNPM, Name: "requests"}
+	assert.Assert(t, again.LoadCache())
+	assert.Equal(t, again.GHOrg, "npm-org")
+}
+
+func Test_ModuleCacheSource(t *testing.T) {
+	upstream := &Module{Ecosystem: EcosystemCrates, Name: "foo"}
+	assert.Assert(t, !syncCache(t, upstream, func() { upstream.GHOrg, upstream.GHRepo, upstream.Archived = "foo-rs", "foo", true }))
+
+	// a git dependency on a fork presets its repository
+	fork := &Module{Ecosystem: EcosystemCrates, Name: "foo", GHOrg: "me", GHRepo: "foo"}
+	assert.Assert(t, !syncCache(t, fork, func() {}))
+	assert.Equal(t, fork.Archived, false)
+
+	again := &Module{Ecosystem: EcosystemCrates, Name: "foo"}
+	assert.Assert(t, again.LoadCache())
+	assert.Equal(t, again.GHOrg, "foo-rs")
+	assert.Equal(t, again.Archived, true)
+
+	// an error found while parsing is kept
+	broken := &Module{Ecosystem: EcosystemCrates, Name: "foo", Err: errors.New("unexpected source")}
+	assert.Assert(t, broken.LoadCache())
+	assert.ErrorContains(t, broken.Err, "unexpected source")
+	assert.Equal(t, broken.GHOrg, "")
+}
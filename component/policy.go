@@ -0,0 +1,157 @@
+package component
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EcosystemDefault   = "default"
+	EcosystemImage     = "image"
+	EcosystemGo        = "go"
+	EcosystemNPM       = "npm"
+	EcosystemPyPI      = "pypi"
+	EcosystemRubyGems  = "rubygems"
+	EcosystemCrates    = "crates"
+	EcosystemMaven     = "maven"
+	EcosystemPackagist = "packagist"
+	EcosystemNuGet     = "nuget"
+	EcosystemActions   = "actions"
+)
+
+var ecosystems = []string{
+	EcosystemDefault,
+	EcosystemImage,
+	EcosystemGo,
+	EcosystemNPM,
+	EcosystemPyPI,
+	EcosystemRubyGems,
+	EcosystemCrates,
+	EcosystemMaven,
+	EcosystemPackagist,
+	EcosystemNuGet,
+	EcosystemActions,
+}
+
+type RuleConfig struct {
+	Enabled  bool
+	Severity Verdict
+}
+
+// Policy decides which findings are reported and how severe they are.
+type Policy struct {
+	// StaleDays is the staleness threshold per ecosystem, EcosystemDefault is used as fallback.
+	StaleDays map[string]int
+	// EOLSoonDays is the window before an EOL date in which language-eol-soon is reported.
+	EOLSoonDays int
+	Rules       map[string]RuleConfig
+	Ignores     []*Ignore
+	// Dir is the directory of the policy file, ignore paths are relative to it.
+	Dir string
+}
+
+var DefaultPolicy = Policy{
+	StaleDays: map[string]int{
+		EcosystemDefault: 730,
+	},
+	EOLSoonDays: 730,
+	Rules: map[string]RuleConfig{
+		RuleArchived:        {Enabled: true, Severity: VerdictWarn},
+		RuleStalePush:       {Enabled: true, Severity: VerdictWarn},
+		RuleStaleImage:      {Enabled: true, Severity: VerdictWarn},
+		RuleLanguageEOL:     {Enabled: true, Severity: VerdictWarn},
+		RuleLanguageEOLSoon: {Enabled: true, Severity: VerdictWarn},
+		RuleNotLatestPatch:  {Enabled: true, Severity: VerdictWarn},
+		RuleDigestDrift:     {Enabled: true, Severity: VerdictWarn},
+		RuleImageEOL:        {Enabled: true, Severity: VerdictWarn},
+		RuleImageEOLSoon:    {Enabled: true, Severity: VerdictWarn},
+		RuleUnpinnedAction:  {Enabled: true, Severity: VerdictInfo},
+		RuleExpiredIgnore:   {Enabled: true, Severity: VerdictWarn},
+	},
+}
+
+type policyFile struct {
+	StaleDays   map[string]int `yaml:"stale_days"`
+	EOLSoonDays *int           `yaml:"eol_soon_days"`
+	Rules       map[string]struct {
+		Enabled  *bool    `yaml:"enabled"`
+		Severity *Verdict `yaml:"severity"`
+	} `yaml:"rules"`
+	Ignore []*ignoreFile `yaml:"ignore"`
+}
+
+// NewPolicy returns a copy of DefaultPolicy which can be modified safely.
+func NewPolicy() *Policy {
+	return &Policy{
+		StaleDays:   maps.Clone(DefaultPolicy.StaleDays),
+		EOLSoonDays: DefaultPolicy.EOLSoonDays,
+		Rules:       maps.Clone(DefaultPolicy.Rules),
+	}
+}
+
+// LoadPolicy reads a policy file. Omitted settings fall back to DefaultPolicy.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &policyFile{}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	p := NewPolicy()
+	for k, v := range f.StaleDays {
+		if !slices.Contains(ecosystems, k) {
+			return nil, fmt.Errorf("%v: unknown ecosystem %v", path, k)
+		}
+		p.StaleDays[k] = v
+	}
+	if f.EOLSoonDays != nil {
+		p.EOLSoonDays = *f.EOLSoonDays
+	}
+	for k, v := range f.Rules {
+		rc, ok := p.Rules[k]
+		if !ok {
+			return nil, fmt.Errorf("%v: unknown rule %v", path, k)
+		}
+		if v.Enabled != nil {
+			rc.Enabled = *v.Enabled
+		}
+		if v.Severity != nil {
+			rc.Severity = *v.Severity
+		}
+		p.Rules[k] = rc
+	}
+	for _, v := range f.Ignore {
+		ig, err := newIgnore(v)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", path, err)
+		}
+		p.Ignores = append(p.Ignores, ig)
+	}
+	if p.Dir, err = filepath.Abs(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Policy) Enabled(rule string) bool {
+	return p.Rules[rule].Enabled
+}
+
+func (p *Policy) Severity(rule string) Verdict {
+	return p.Rules[rule].Severity
+}
+
+func (p *Policy) StaleDaysFor(ecosystem string) int {
+	if d, ok := p.StaleDays[ecosystem]; ok {
+		return d
+	}
+	return p.StaleDays[EcosystemDefault]
+}
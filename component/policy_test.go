@@ -0,0 +1,72 @@
+package component
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_LoadPolicy(t *testing.T) {
+	p, err := LoadPolicy("testdata/compaa.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, p.StaleDaysFor(EcosystemImage), 90)
+	assert.Equal(t, p.StaleDaysFor(EcosystemGo), 730)
+	assert.Equal(t, p.StaleDaysFor(EcosystemNPM), 365)
+	assert.Equal(t, p.EOLSoonDays, 180)
+
+	assert.Assert(t, p.Enabled(RuleArchived))
+	assert.Equal(t, p.Severity(RuleArchived), VerdictError)
+	assert.Assert(t, !p.Enabled(RuleNotLatestPatch))
+	assert.Assert(t, p.Enabled(RuleStalePush))
+	assert.Equal(t, p.Severity(RuleStalePush), VerdictWarn)
+	assert.Equal(t, p.Severity(RuleExpiredIgnore), VerdictInfo)
+
+	assert.Equal(t, DefaultPolicy.Severity(RuleArchived), VerdictWarn)
+}
+
+func Test_PolicySuppress(t *testing.T) {
+	p, err := LoadPolicy("testdata/compaa.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, len(p.Ignores), 2)
+
+	m := &Module{Name: "github.com/pkg/errors", Ecosystem: EcosystemGo, Archived: true}
+	r := m.Evaluate(p)
+	p.Suppress("testdata/sub/go.mod", r)
+	assert.Equal(t, len(r.Findings), 0)
+	assert.Equal(t, len(r.Suppressed), 1)
+	assert.Equal(t, r.Suppressed[0].Rule, RuleArchived)
+	assert.Equal(t, r.Verdict, VerdictOK)
+
+	r = m.Evaluate(p)
+	p.Suppress("testdata/package.json", r)
+	assert.Equal(t, len(r.Suppressed), 0)
+	assert.Equal(t, r.Verdict, VerdictError)
+
+	m = &Module{Name: "github.com/jinzhu/gorm", Ecosystem: EcosystemGo}
+	r = m.Evaluate(p)
+	p.Suppress("testdata/go.mod", r)
+	assert.Equal(t, len(r.Suppressed), 0)
+	assert.Equal(t, len(r.Findings), 2)
+	assert.Equal(t, r.Findings[1].Rule, RuleExpiredIgnore)
+	assert.Equal(t, r.Findings[1].Verdict, VerdictInfo)
+
+	p.Rules[RuleExpiredIgnore] = RuleConfig{Enabled: false}
+	r = m.Evaluate(p)
+	p.Suppress("testdata/go.mod", r)
+	assert.Equal(t, len(r.Findings), 1)
+	assert.Equal(t, len(r.Suppressed), 0)
+}
+
+func Test_NewIgnoreWithoutReason(t *testing.T) {
+	_, err := newIgnore(&ignoreFile{Name: "github.com/pkg/errors"})
+	assert.ErrorContains(t, err, "needs a reason")
+}
+
+func Test_NewIgnoreUnknownNames(t *testing.T) {
+	_, err := newIgnore(&ignoreFile{Name: "github.com/pkg/errors", Rule: "archive", Reason: "typo"})
+	assert.ErrorContains(t, err, "unknown rule archive")
+	_, err = newIgnore(&ignoreFile{Name: "github.com/pkg/errors", Ecosystem: "golang", Reason: "typo"})
+	assert.ErrorContains(t, err, "unknown ecosystem golang")
+	_, err = newIgnore(&ignoreFile{Name: "github.com/pkg/errors", Ecosystem: EcosystemGo, Rule: RuleArchived, Reason: "kept"})
+	assert.NilError(t, err)
+}
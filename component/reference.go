@@ -0,0 +1,100 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// https://github.com/distribution/reference/blob/main/reference.go
+var (
+	referencePathComponent = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	referenceDomain        = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*|\[[a-fA-F0-9:]+\])(?::[0-9]+)?$`)
+	referenceTag           = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	referenceDigest        = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+)
+
+const referenceNameMaxLength = 255
+
+// reference is an image reference split into its parts, normalized the way docker does.
+type reference struct {
+	domain string
+	path   string
+	tag    string
+	digest string
+}
+
+// parseReference parses `[domain/]path[:tag][@digest]`.
+// A reference without domain is on Docker Hub, where single component paths are official images under library/.
+// The tag defaults to latest unless a digest is given.
+func parseReference(s string) (*reference, error) {
+	r := &reference{}
+	name := s
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, r.digest = name[:i], name[i+1:]
+		if !referenceDigest.MatchString(r.digest) {
+			return nil, fmt.Errorf("invalid digest %q", r.digest)
+		}
+	}
+	// a colon after the last slash separates the tag, an earlier one is the port of the domain
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, r.tag = name[:i], name[i+1:]
+		if !referenceTag.MatchString(r.tag) {
+			return nil, fmt.Errorf("invalid tag %q", r.tag)
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("repository name is empty")
+	}
+	if len(name) > referenceNameMaxLength {
+		return nil, fmt.Errorf("repository name must not be longer than %v characters", referenceNameMaxLength)
+	}
+
+	r.domain, r.path = splitDomain(name)
+	if r.domain != DockerHubRegistry && !referenceDomain.MatchString(r.domain) {
+		return nil, fmt.Errorf("invalid domain %q", r.domain)
+	}
+	for _, c := range strings.Split(r.path, "/") {
+		if !referencePathComponent.MatchString(c) {
+			if strings.ToLower(c) == c {
+				return nil, fmt.Errorf("invalid path component %q", c)
+			}
+			return nil, fmt.Errorf("repository name must be lowercase")
+		}
+	}
+
+	if r.tag == "" && r.digest == "" {
+		r.tag = DefaultTag
+	}
+	return r, nil
+}
+
+// splitDomain splits the domain from name. The first component is a domain
+// if it contains a dot or a colon, is localhost, or has upper case letters.
+func splitDomain(name string) (domain, path string) {
+	i := strings.Index(name, "/")
+	if i < 0 || !strings.ContainsAny(name[:i], ".:") && name[:i] != "localhost" && strings.ToLower(name[:i]) == name[:i] {
+		domain, path = DockerHubRegistry, name
+	} else {
+		domain, path = name[:i], name[i+1:]
+	}
+	if domain == "index.docker.io" {
+		domain = DockerHubRegistry
+	}
+	if domain == DockerHubRegistry && !strings.Contains(path, "/") {
+		path = DockerHubOfficialNamespace + "/" + path
+	}
+	return
+}
+
+// String returns the canonical form like docker.io/library/alpine:3.19@sha256:xxxx.
+func (r *reference) String() string {
+	s := r.domain + "/" + r.path
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TypeModule   = "module"
+	TypeImage    = "image"
+	TypeLanguage = "language"
+)
+
+const (
+	RuleArchived        = "archived-repo"
+	RuleStalePush       = "stale-push"
+	RuleStaleImage      = "stale-image"
+	RuleLanguageEOL     = "language-eol"
+	RuleLanguageEOLSoon = "language-eol-soon"
+	RuleNotLatestPatch  = "not-latest-patch"
+	RuleDigestDrift     = "digest-drift"
+	RuleImageEOL        = "image-eol"
+	RuleImageEOLSoon    = "image-eol-soon"
+	RuleExpiredIgnore   = "expired-ignore"
+	RuleUnpinnedAction  = "unpinned-action"
+)
+
+type Verdict int
+
+const (
+	VerdictOK Verdict = iota
+	VerdictInfo
+	VerdictWarn
+	VerdictError
+)
+
+var verdictNames = map[Verdict]string{
+	VerdictOK:    "ok",
+	VerdictInfo:  "info",
+	VerdictWarn:  "warn",
+	VerdictError: "error",
+}
+
+func (v Verdict) String() string {
+	return verdictNames[v]
+}
+
+func (v Verdict) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func (v *Verdict) UnmarshalText(b []byte) error {
+	for k, name := range verdictNames {
+		if name == string(b) {
+			*v = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown verdict %q", b)
+}
+
+type Finding struct {
+	Rule    string  `json:"rule,omitempty"`
+	Verdict Verdict `json:"verdict"`
+	Message string  `json:"message"`
+}
+
+// Suppression is a finding silenced by an ignore entry of the policy.
+type Suppression struct {
+	Finding
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until,omitzero"`
+}
+
+// Result is the evaluated state of a single component.
+type Result struct {
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Ecosystem   string    `json:"ecosystem,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	NewestTag   string    `json:"newest_tag,omitempty"`
+	NewestPatch string    `json:"newest_patch,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	GHOrg       string    `json:"github_org,omitempty"`
+	GHRepo      string    `json:"github_repo,omitempty"`
+	LastPush    time.Time `json:"last_push,omitzero"`
+	LastUpdate  time.Time `json:"last_update,omitzero"`
+	EOLDate     time.Time `json:"eol_date,omitzero"`
+	EOL         bool      `json:"eol,omitempty"`
+	Archived    bool      `json:"archived"`
+	Indirect    bool      `json:"indirect,omitempty"`
+	DepPath     []string  `json:"dependency_path,omitempty"`
+	Verdict     Verdict   `json:"verdict"`
+	Reason      string    `json:"reason,omitempty"`
+	Findings    []Finding `json:"findings,omitempty"`
+
+	Suppressed []Suppression `json:"suppressed,omitempty"`
+	Baselined  []Finding     `json:"baselined,omitempty"`
+}
+
+// Add records a finding. rule is empty for errors and notes that are not policy violations.
+func (r *Result) Add(rule string, v Verdict, format string, a ...interface{}) {
+	f := Finding{Rule: rule, Verdict: v, Message: fmt.Sprintf(format, a...)}
+	r.Findings = append(r.Findings, f)
+	r.update()
+}
+
+// Remove drops the findings for which match returns true and returns them.
+func (r *Result) Remove(match func(f *Finding) bool) []Finding {
+	var kept, removed []Finding
+	for _, f := range r.Findings {
+		if match(&f) {
+			removed = append(removed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	r.Findings = kept
+	r.update()
+	return removed
+}
+
+func (r *Result) update() {
+	r.Verdict = VerdictOK
+	reasons := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		r.Verdict = max(r.Verdict, f.Verdict)
+		reasons = append(reasons, f.Message)
+	}
+	r.Reason = strings.Join(reasons, "; ")
+}
+
+func (r *Result) Logging(logger Logger) {
+	if logger == nil {
+		logger = &DefaultLogger{}
+	}
+
+	for _, f := range r.Findings {
+		switch f.Verdict {
+		case VerdictError:
+			logger.Error("├ ERROR: %v\n", f.Message)
+		case VerdictWarn:
+			logger.Warn("├ WARN: %v\n", f.Message)
+		case VerdictInfo:
+			logger.Debug("├ INFO: %v\n", f.Message)
+		}
+	}
+}
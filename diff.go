@@ -0,0 +1,287 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/izziiyt/compaa/component"
+	"github.com/izziiyt/compaa/handler"
+	"github.com/izziiyt/compaa/report"
+)
+
+// runDiff reports health regressions introduced by head compared to base.
+// The directories of manifests are extracted from the git object store for both revisions, so no checkout is needed
+// and manifests can read the files next to them, like pyproject.toml of poetry.lock or includes of requirements.txt.
+// Only components which were added or changed in head are synced. Findings accepted by bl, which may be nil, are hidden.
+func runDiff(ctx context.Context, r *Router, policy *component.Policy, bl *report.Baseline, base, head string, w report.Writer) (component.Verdict, bool, error) {
+	worst := component.VerdictOK
+	failed := false
+
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return worst, failed, err
+	}
+	root := strings.TrimSpace(string(top))
+	cwd, err := os.Getwd()
+	if err != nil {
+		return worst, failed, err
+	}
+	headBlobs, err := manifestBlobs(r, head)
+	if err != nil {
+		return worst, failed, err
+	}
+	baseBlobs, err := manifestBlobs(r, base)
+	if err != nil {
+		return worst, failed, err
+	}
+
+	tmp, err := os.MkdirTemp("", "compaa-diff")
+	if err != nil {
+		return worst, failed, err
+	}
+	defer os.RemoveAll(tmp)
+	headDir, baseDir := filepath.Join(tmp, "head"), filepath.Join(tmp, "base")
+	if err := extractTree(root, head, headDir, slices.Collect(maps.Keys(headBlobs))); err != nil {
+		return worst, failed, err
+	}
+	if err := extractTree(root, base, baseDir, slices.Collect(maps.Keys(baseBlobs))); err != nil {
+		return worst, failed, err
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(headBlobs)) {
+		baseBlob, inBase := baseBlobs[p]
+		baseFile := ""
+		if inBase {
+			baseFile = filepath.Join(baseDir, filepath.FromSlash(p))
+		}
+
+		abs := filepath.Join(root, p)
+		m := diffManifest(ctx, r.Route(p), policy, p, abs, baseFile, filepath.Join(headDir, filepath.FromSlash(p)))
+		// an unchanged manifest may still read changed files, so it is reported only if it has changed components
+		// or it broke with them
+		if baseBlob == headBlobs[p] && len(m.Components) == 0 {
+			if m.Error == "" {
+				continue
+			}
+			if _, err := r.Route(p).LookUp(baseFile); err != nil {
+				continue
+			}
+		}
+		if bl != nil {
+			// baseline paths are relative to the working directory, where the baseline was written
+			rel, err := filepath.Rel(cwd, abs)
+			if err != nil {
+				rel = p
+			}
+			bl.Filter(&report.Manifest{Path: rel, Components: m.Components})
+		}
+		worst = max(worst, m.Verdict())
+		failed = failed || m.Error != ""
+		if err := w.Write(m); err != nil {
+			return worst, failed, err
+		}
+	}
+	return worst, failed, w.Close()
+}
+
+// diffManifest evaluates the components of headFile which are absent from baseFile or declared with another version.
+// Findings which the base version already had are not regressions and are dropped.
+func diffManifest(ctx context.Context, h handler.Handler, policy *component.Policy, path, abs, baseFile, headFile string) *report.Manifest {
+	m := &report.Manifest{
+		Path:       path,
+		Components: []*component.Result{},
+	}
+
+	hcs, err := h.LookUp(headFile)
+	if err != nil {
+		m.Error = err.Error()
+		return m
+	}
+	bcs := map[string]component.Component{}
+	if baseFile != "" {
+		// a base manifest which can't be parsed makes every component new
+		cs, _ := h.LookUp(baseFile)
+		for _, c := range cs {
+			key, _ := identity(c)
+			bcs[key] = c
+		}
+	}
+
+	var changed, before []component.Component
+	for _, c := range hcs {
+		key, version := identity(c)
+		bc, ok := bcs[key]
+		if ok {
+			if _, v := identity(bc); v == version {
+				continue
+			}
+			before = append(before, bc)
+		}
+		changed = append(changed, c)
+	}
+	handler.Sync(h, ctx, slices.Concat(changed, before))
+
+	for _, c := range changed {
+		res := handler.Evaluate(c, abs, policy)
+		key, _ := identity(c)
+		if bc, ok := bcs[key]; ok {
+			old := bc.Evaluate(policy)
+			res.Remove(func(f *component.Finding) bool {
+				return f.Rule != "" && hasRule(old, f.Rule)
+			})
+		}
+		m.Components = append(m.Components, res)
+	}
+	return m
+}
+
+func identity(c component.Component) (key, version string) {
+	switch v := c.(type) {
+	case *component.Module:
+		return component.TypeModule + " " + v.Ecosystem + " " + v.Name, v.Version
+	case *component.Image:
+		return component.TypeImage + " " + v.Registry + "/" + path.Join(v.Namespace, v.Repository), v.Tag + "@" + v.Digest
+	case *component.Language:
+		return component.TypeLanguage + " " + v.Name, v.Version
+	default:
+		return fmt.Sprintf("%T", c), ""
+	}
+}
+
+func hasRule(r *component.Result, rule string) bool {
+	for _, f := range r.Findings {
+		if f.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestBlobs maps the files of rev which the router recognizes, relative to the repository root, to their blob hashes.
+func manifestBlobs(r *Router, rev string) (map[string]string, error) {
+	b, err := git("ls-tree", "-r", "-z", "--full-tree", rev)
+	if err != nil {
+		return nil, err
+	}
+	blobs := map[string]string{}
+	for _, line := range strings.Split(string(b), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, p, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || excludedPath(p) {
+			continue
+		}
+		if r.Route(p) != nil {
+			blobs[p] = fields[2]
+		}
+	}
+	return blobs, nil
+}
+
+func excludedPath(path string) bool {
+	dirs := strings.Split(path, "/")
+	for _, d := range dirs[:len(dirs)-1] {
+		if excludedPatterns(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractTree writes the files of rev in the repository at root which sit next to manifests under dir.
+// The archive is streamed and limited to the directories of manifests, so the repository is neither checked out
+// nor held in memory. Symbolic links and excluded directories are skipped.
+func extractTree(root, rev, dir string, manifests []string) error {
+	if len(manifests) == 0 {
+		return nil
+	}
+	args := append([]string{"-C", root, "archive", "--format=tar", rev, "--"}, manifestPathspecs(manifests)...)
+	cmd := exec.Command("git", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = untar(stdout, dir)
+	if err == nil {
+		// the padding after the end of the archive
+		_, err = io.Copy(io.Discard, stdout)
+	} else {
+		//nolint:errcheck
+		cmd.Process.Kill()
+	}
+	if werr := cmd.Wait(); err == nil && werr != nil {
+		err = fmt.Errorf("git archive %v: %w: %v", rev, werr, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// manifestPathspecs matches the files directly in the directories of manifests. Every pathspec matches at least the manifest itself.
+func manifestPathspecs(manifests []string) []string {
+	escape := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+	specs := map[string]bool{}
+	for _, p := range manifests {
+		spec := ":(glob)*"
+		if d := path.Dir(p); d != "." {
+			spec = ":(glob)" + escape.Replace(d) + "/*"
+		}
+		specs[spec] = true
+	}
+	return slices.Sorted(maps.Keys(specs))
+}
+
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) || excludedPath(hdr.Name) {
+			continue
+		}
+		out := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	b, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %v: %w: %v", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return b, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+// lineHandler reads "name version" lines as modules. Modules whose names end with "-archived" sync as archived.
+type lineHandler struct{}
+
+func (h *lineHandler) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		name, version, _ := strings.Cut(l, " ")
+		buf = append(buf, &component.Module{Ecosystem: "diff-test", Name: name, Version: version, Line: i + 1})
+	}
+	return
+}
+
+func (h *lineHandler) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	m := c.(*component.Module)
+	m.LastPush = time.Now()
+	m.Archived = strings.HasSuffix(m.Name, "-archived")
+	return m
+}
+
+func Test_DiffManifest(t *testing.T) {
+	dir := t.TempDir()
+	base, head := filepath.Join(dir, "base"), filepath.Join(dir, "head")
+	assert.NilError(t, os.WriteFile(base, []byte("same 1\nbumped-archived 1\n"), 0644))
+	assert.NilError(t, os.WriteFile(head, []byte("same 1\nbumped-archived 2\nnew-archived 1\n"), 0644))
+
+	m := diffManifest(context.Background(), &lineHandler{}, component.NewPolicy(), "deps.txt", "/repo/deps.txt", base, head)
+	assert.Equal(t, m.Error, "")
+	assert.Equal(t, len(m.Components), 2)
+	// the archived finding of a bumped module is no regression as the base had it
+	assert.Equal(t, m.Components[0].Name, "bumped-archived")
+	assert.Equal(t, len(m.Components[0].Findings), 0)
+	assert.Equal(t, m.Components[1].Name, "new-archived")
+	assert.Equal(t, m.Components[1].Findings[0].Rule, component.RuleArchived)
+
+	// without a base every component is new
+	m = diffManifest(context.Background(), &lineHandler{}, component.NewPolicy(), "deps.txt", "/repo/deps.txt", "", head)
+	assert.Equal(t, len(m.Components), 3)
+
+	m = diffManifest(context.Background(), &lineHandler{}, component.NewPolicy(), "deps.txt", "/repo/deps.txt", base, filepath.Join(dir, "missing"))
+	assert.Assert(t, m.Error != "")
+}
+
+func Test_Identity(t *testing.T) {
+	key, version := identity(&component.Module{Ecosystem: component.EcosystemNPM, Name: "react", Version: "18.2.0"})
+	assert.Equal(t, key, "module npm react")
+	assert.Equal(t, version, "18.2.0")
+	other, _ := identity(&component.Module{Ecosystem: component.EcosystemPyPI, Name: "react", Version: "18.2.0"})
+	assert.Assert(t, key != other)
+
+	key, version = identity((&component.Image{}).FromRawString("alpine:3.19"))
+	assert.Equal(t, key, "image docker.io/library/alpine")
+	assert.Equal(t, version, "3.19@")
+
+	key, version = identity(&component.Language{Name: "python", Version: "3.12"})
+	assert.Equal(t, key, "language python")
+	assert.Equal(t, version, "3.12")
+}
+
+func Test_ExtractTree(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		b, err := cmd.CombinedOutput()
+		assert.NilError(t, err, string(b))
+	}
+	run("init", "-q")
+	assert.NilError(t, os.MkdirAll(filepath.Join(repo, "svc", "node_modules"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(repo, "svc", "requirements.txt"), []byte("-r base.txt\n"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(repo, "svc", "base.txt"), []byte("requests==2.31.0\n"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(repo, "svc", "node_modules", "x.json"), []byte("{}"), 0644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(repo, "docs"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(repo, "docs", "index.md"), []byte("# docs\n"), 0644))
+	run("add", "-A")
+	run("commit", "-q", "-m", "init")
+
+	out := filepath.Join(t.TempDir(), "head")
+	assert.NilError(t, extractTree(repo, "HEAD", out, []string{"svc/requirements.txt"}))
+	b, err := os.ReadFile(filepath.Join(out, "svc", "base.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "requests==2.31.0\n")
+	_, err = os.Stat(filepath.Join(out, "svc", "requirements.txt"))
+	assert.NilError(t, err)
+	// only the directories of manifests are extracted, without their subdirectories
+	_, err = os.Stat(filepath.Join(out, "svc", "node_modules"))
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(out, "docs"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	assert.ErrorContains(t, extractTree(repo, "HEAD", out, []string{"gone/go.mod"}), "did not match")
+}
+
+func Test_ManifestPathspecs(t *testing.T) {
+	assert.DeepEqual(t, manifestPathspecs([]string{"go.mod", "go.sum", "a/b/package.json", "a/b/yarn.lock", "x[1]/Cargo.toml"}),
+		[]string{":(glob)*", ":(glob)a/b/*", `:(glob)x\[1]/*`})
+}
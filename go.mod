@@ -3,9 +3,11 @@ module github.com/izziiyt/compaa
 go 1.24.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/fatih/color v1.18.0
 	github.com/google/go-github/v60 v60.0.0
 	golang.org/x/mod v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 	gotest.tools/v3 v3.5.2
 )
 
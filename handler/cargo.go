@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// CargoTOML reads the dependencies, dev-dependencies and build-dependencies of Cargo.toml, including platform specific ones
+// and the dependencies of a workspace. Dependencies and rust-version inherited from the workspace are resolved against its root.
+type CargoTOML struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+type cargoDependencies map[string]any
+
+type cargoManifest struct {
+	Package struct {
+		// "1.74" or {workspace = true}
+		RustVersion any `toml:"rust-version"`
+	} `toml:"package"`
+	Dependencies      cargoDependencies `toml:"dependencies"`
+	DevDependencies   cargoDependencies `toml:"dev-dependencies"`
+	BuildDependencies cargoDependencies `toml:"build-dependencies"`
+	Target            map[string]struct {
+		Dependencies      cargoDependencies `toml:"dependencies"`
+		DevDependencies   cargoDependencies `toml:"dev-dependencies"`
+		BuildDependencies cargoDependencies `toml:"build-dependencies"`
+	} `toml:"target"`
+	Workspace *struct {
+		Package struct {
+			RustVersion string `toml:"rust-version"`
+		} `toml:"package"`
+		Dependencies cargoDependencies `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+var (
+	// headers of dependency tables like [dependencies] or [target.'cfg(unix)'.dev-dependencies]
+	cargoTableRegexp = regexp.MustCompile(`(?:^|\.)(?:dev-|build-)?dependencies$`)
+	// headers of a dependency like [dependencies.serde]
+	cargoDependencyTableRegexp = regexp.MustCompile(`(?:^|\.)(?:dev-|build-)?dependencies\.([\w-]+)$`)
+	cargoKeyRegexp             = regexp.MustCompile(`^\s*([\w-]+)(?:\.workspace)?\s*=`)
+)
+
+func parseCargoManifest(path string) (*cargoManifest, []byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &cargoManifest{}
+	if _, err := toml.Decode(string(b), m); err != nil {
+		return nil, nil, err
+	}
+	return m, b, nil
+}
+
+func (h *CargoTOML) LookUp(path string) (buf []component.Component, err error) {
+	m, b, err := parseCargoManifest(path)
+	if err != nil {
+		return
+	}
+	lines := cargoDependencyLines(b)
+
+	// a member inherits from the closest workspace root above it
+	ws := m
+	if m.Workspace == nil {
+		ws = cargoWorkspaceRoot(filepath.Dir(path))
+	}
+	var wsDeps cargoDependencies
+	if ws != nil && ws.Workspace != nil {
+		wsDeps = ws.Workspace.Dependencies
+	}
+
+	rustVersion, _ := m.Package.RustVersion.(string)
+	_, inherit := m.Package.RustVersion.(map[string]any)
+	if rustVersion == "" && (inherit || m.Workspace != nil) && ws != nil && ws.Workspace != nil {
+		rustVersion = ws.Workspace.Package.RustVersion
+	}
+	if rustVersion != "" {
+		buf = append(buf, &component.Language{Name: "rust", Version: rustVersion, Line: matchLine(b, cargoRustVersionRegexp)})
+	}
+
+	tables := []cargoDependencies{m.Dependencies, m.DevDependencies, m.BuildDependencies}
+	for _, k := range slices.Sorted(maps.Keys(m.Target)) {
+		t := m.Target[k]
+		tables = append(tables, t.Dependencies, t.DevDependencies, t.BuildDependencies)
+	}
+	if m.Workspace != nil {
+		tables = append(tables, m.Workspace.Dependencies)
+	}
+	seen := map[string]bool{}
+	for _, deps := range tables {
+		for _, key := range slices.Sorted(maps.Keys(deps)) {
+			mod := cargoModule(key, deps[key], wsDeps)
+			if seen[mod.Name] {
+				continue
+			}
+			seen[mod.Name] = true
+			mod.Line = lines[key]
+			buf = append(buf, mod)
+		}
+	}
+	return
+}
+
+var cargoRustVersionRegexp = regexp.MustCompile(`^\s*rust-version\s*[.=]`)
+
+// cargoModule converts a dependency, "1.0" or {version = "1.0", package = "real-name", git = "..."}, into a module.
+// A dependency with {workspace = true} takes the declaration of the workspace.
+func cargoModule(key string, v any, wsDeps cargoDependencies) *component.Module {
+	m := &component.Module{Ecosystem: component.EcosystemCrates, Name: key}
+	switch d := v.(type) {
+	case string:
+		m.Version = d
+	case map[string]any:
+		if inherit, _ := d["workspace"].(bool); inherit {
+			if w, ok := wsDeps[key]; ok {
+				return cargoModule(key, w, nil)
+			}
+			m.Err = fmt.Errorf("%v is not a dependency of the workspace", key)
+			return m
+		}
+		if p, ok := d["package"].(string); ok {
+			m.Name = p
+		}
+		m.Version, _ = d["version"].(string)
+		if p, ok := d["path"].(string); ok && m.Version == "" {
+			m.Err = fmt.Errorf("%w: installed from path %v", component.ErrSkip, p)
+		} else if g, ok := d["git"].(string); ok {
+			if gh := component.GitHubRepositoryRegexp.FindStringSubmatch(g); gh != nil {
+				m.GHOrg, m.GHRepo = gh[1], gh[2]
+			} else {
+				m.Err = fmt.Errorf("%w: installed from git %v", component.ErrSkip, g)
+			}
+		} else if r, ok := d["registry"].(string); ok && r != "crates-io" {
+			m.Err = fmt.Errorf("%w: installed from registry %v", component.ErrSkip, r)
+		}
+	}
+	return m
+}
+
+// cargoWorkspaceRoot returns the manifest of the closest workspace root in dir or above, or nil if there is none.
+func cargoWorkspaceRoot(dir string) *cargoManifest {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+		if m, _, err := parseCargoManifest(filepath.Join(dir, "Cargo.toml")); err == nil && m.Workspace != nil {
+			return m
+		}
+	}
+}
+
+// cargoDependencyLines maps the keys of dependencies to the lines they are first declared at.
+func cargoDependencyLines(b []byte) map[string]int {
+	lines := map[string]int{}
+	set := func(k string, n int) {
+		if _, ok := lines[k]; !ok {
+			lines[k] = n
+		}
+	}
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			if m := cargoDependencyTableRegexp.FindStringSubmatch(table); m != nil {
+				set(m[1], n)
+			}
+			continue
+		}
+		if !cargoTableRegexp.MatchString(table) {
+			continue
+		}
+		if m := cargoKeyRegexp.FindStringSubmatch(line); m != nil {
+			set(m[1], n)
+		}
+	}
+	return lines
+}
+
+func (h *CargoTOML) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithCratesIO(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// CargoLock reads Cargo.lock. The dependencies of the workspace members, the packages without a source, are the direct dependencies.
+type CargoLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+func (h *CargoLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parseCargoLock(b)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parseCargoLock(b []byte) (*lockGraph, error) {
+	lock := struct {
+		Package []struct {
+			Name         string   `toml:"name"`
+			Version      string   `toml:"version"`
+			Source       string   `toml:"source"`
+			Dependencies []string `toml:"dependencies"`
+		} `toml:"package"`
+	}{}
+	if _, err := toml.Decode(string(b), &lock); err != nil {
+		return nil, err
+	}
+	lines := tomlArrayLines(b, "package")
+
+	// dependencies are "name" if a single version is locked, "name version" or "name version (source)" otherwise
+	versions := map[string][]string{}
+	for _, p := range lock.Package {
+		versions[p.Name] = append(versions[p.Name], p.Version)
+	}
+	resolve := func(d string) string {
+		fields := strings.Fields(d)
+		if len(fields) > 1 {
+			return fields[0] + " " + fields[1]
+		}
+		if vs := versions[fields[0]]; len(vs) > 0 {
+			return fields[0] + " " + vs[0]
+		}
+		return d
+	}
+
+	g := newLockGraph(component.EcosystemCrates)
+	for i, p := range lock.Package {
+		var deps []string
+		for _, d := range p.Dependencies {
+			deps = append(deps, resolve(d))
+		}
+		if p.Source == "" {
+			// a workspace member
+			g.roots = append(g.roots, deps...)
+			continue
+		}
+		lp := &lockPackage{name: p.Name, version: p.Version, deps: deps}
+		if i < len(lines) {
+			lp.line = lines[i]
+		}
+		switch {
+		case strings.HasPrefix(p.Source, "git+"):
+			if gh := component.GitHubRepositoryRegexp.FindStringSubmatch(p.Source); gh != nil {
+				lp.ghOrg, lp.ghRepo = gh[1], gh[2]
+			} else {
+				lp.err = fmt.Errorf("%w: installed from %v", component.ErrSkip, p.Source)
+			}
+		case !strings.Contains(p.Source, "github.com/rust-lang/crates.io-index") && !strings.Contains(p.Source, "index.crates.io"):
+			lp.err = fmt.Errorf("%w: installed from %v", component.ErrSkip, p.Source)
+		}
+		g.pkgs[p.Name+" "+p.Version] = lp
+	}
+	return g, nil
+}
+
+func (h *CargoLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithCratesIO(c, ctx, h.HTTPClient, h.GCli)
+}
+
+func syncWithCratesIO(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		// git dependencies already know their repositories
+		if v.GHOrg == "" {
+			v = v.SyncWithCratesIO(ctx, cli)
+		}
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, cli)
+		return v
+	default:
+		return v
+	}
+}
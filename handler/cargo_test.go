@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_CargoTOMLLookUp(t *testing.T) {
+	h := &CargoTOML{}
+	as, err := h.LookUp("testdata/cargo/crates/app/Cargo.toml")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "rust")
+	assert.Equal(t, l.Version, "1.74")
+	assert.Equal(t, l.Line, 5)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"anyhow@1.0.80", "gitdep@", "serde_json@1.0", "mylib@!", "regex@1.10", "serde@1.0", "tokio@1.36", "cc@1.0", "nix@0.27",
+	})
+	gitdep := as[2].(*component.Module)
+	assert.Equal(t, gitdep.GHOrg, "example")
+	assert.Equal(t, gitdep.GHRepo, "gitdep")
+	assert.Equal(t, gitdep.Line, 12)
+	assert.Equal(t, as[3].(*component.Module).Line, 10)
+	assert.Equal(t, as[5].(*component.Module).Line, 14)
+	assert.Equal(t, as[9].(*component.Module).Line, 24)
+	assert.Equal(t, as[1].(*component.Module).Ecosystem, component.EcosystemCrates)
+
+	as, err = h.LookUp("testdata/cargo/Cargo.toml")
+	assert.NilError(t, err)
+	assert.Equal(t, as[0].(*component.Language).Line, 6)
+	assert.DeepEqual(t, describeModules(as), []string{"serde@1.0", "tokio@1.36"})
+}
+
+func Test_CargoLockLookUp(t *testing.T) {
+	h := &CargoLock{}
+	as, err := h.LookUp("testdata/cargo/Cargo.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{"anyhow@1.0.80", "gitdep@0.2.0", "serde@1.0.197", "syn@1.0.109"})
+	gitdep := as[1].(*component.Module)
+	assert.Equal(t, gitdep.GHOrg, "example")
+	assert.Equal(t, gitdep.Line, 21)
+
+	h.Transitive = true
+	as, err = h.LookUp("testdata/cargo/Cargo.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"anyhow@1.0.80", "gitdep@0.2.0", "serde@1.0.197", "syn@1.0.109", "~serde_derive@1.0.197", "~proc-macro2@1.0.78",
+	})
+	assert.DeepEqual(t, as[5].(*component.Module).Path, []string{"serde", "serde_derive"})
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// ComposerJSON reads require and require-dev of composer.json. The php requirement is checked as a runtime.
+type ComposerJSON struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+	Config     struct {
+		Platform map[string]string `json:"platform"`
+	} `json:"config"`
+}
+
+// isComposerPlatform reports whether a requirement is on the platform, like php or ext-json, instead of a package.
+func isComposerPlatform(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+func (h *ComposerJSON) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	c := &composerJSON{}
+	if err = json.Unmarshal(b, c); err != nil {
+		return
+	}
+	lines := strings.Split(string(b), "\n")
+
+	// config.platform pins the php dependencies are resolved for, the require of php is a range
+	if v, ok := c.Config.Platform["php"]; ok {
+		buf = append(buf, &component.Language{Name: "php", Version: v, Line: keyLine(lines, "platform", "php")})
+	} else if v, ok := rangeFloor("php", c.Require["php"]); ok {
+		buf = append(buf, &component.Language{Name: "php", Version: v, Line: keyLine(lines, "require", "php")})
+	}
+
+	seen := map[string]bool{}
+	for _, t := range []struct {
+		section string
+		deps    map[string]string
+	}{{"require", c.Require}, {"require-dev", c.RequireDev}} {
+		section, deps := t.section, t.deps
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			if isComposerPlatform(name) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			buf = append(buf, &component.Module{
+				Ecosystem: component.EcosystemPackagist,
+				Name:      name,
+				Version:   deps[name],
+				Line:      keyLine(lines, section, name),
+			})
+		}
+	}
+	return
+}
+
+func (h *ComposerJSON) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPackagist(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// ComposerLock reads packages and packages-dev of composer.lock. The direct dependencies are taken from the sibling composer.json.
+// Packages are checked against the repositories of their sources, falling back to Packagist.
+type ComposerLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+type composerLockPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+	Source  struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"source"`
+	Dist struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"dist"`
+}
+
+func (h *ComposerLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var direct map[string]string
+	if cb, err := os.ReadFile(filepath.Join(filepath.Dir(path), "composer.json")); err == nil {
+		c := &composerJSON{}
+		if json.Unmarshal(cb, c) == nil {
+			direct = map[string]string{}
+			maps.Copy(direct, c.Require)
+			maps.Copy(direct, c.RequireDev)
+		}
+	}
+	g, err := parseComposerLock(b, direct)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parseComposerLock(b []byte, direct map[string]string) (*lockGraph, error) {
+	lock := struct {
+		Packages    []composerLockPackage `json:"packages"`
+		PackagesDev []composerLockPackage `json:"packages-dev"`
+	}{}
+	if err := json.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+	// a name may appear in require of other packages first
+	lines := strings.Split(string(b), "\n")
+	nameLine := func(name string) int {
+		for i, l := range lines {
+			if strings.Contains(l, `"name": `+strconv.Quote(name)) {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	g := newLockGraph(component.EcosystemPackagist)
+	for _, p := range slices.Concat(lock.Packages, lock.PackagesDev) {
+		lp := &lockPackage{
+			name:    p.Name,
+			version: strings.TrimPrefix(p.Version, "v"),
+			line:    nameLine(p.Name),
+		}
+		if m := component.GitHubRepositoryRegexp.FindStringSubmatch(p.Source.URL); m != nil {
+			lp.ghOrg, lp.ghRepo = m[1], m[2]
+		} else if p.Dist.Type == "path" {
+			lp.err = fmt.Errorf("%w: installed from path %v", component.ErrSkip, p.Dist.URL)
+		}
+		for _, d := range slices.Sorted(maps.Keys(p.Require)) {
+			if !isComposerPlatform(d) {
+				lp.deps = append(lp.deps, d)
+			}
+		}
+		g.pkgs[p.Name] = lp
+	}
+	for _, name := range slices.Sorted(maps.Keys(direct)) {
+		if _, ok := g.pkgs[name]; ok {
+			g.roots = append(g.roots, name)
+		}
+	}
+	if len(g.roots) == 0 {
+		// without composer.json, packages nothing depends on are taken as direct dependencies
+		g.orphanRoots()
+	}
+	return g, nil
+}
+
+func (h *ComposerLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPackagist(c, ctx, h.HTTPClient, h.GCli)
+}
+
+func syncWithPackagist(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		// locked packages know the repositories of their sources
+		if v.GHOrg == "" {
+			v = v.SyncWithPackagist(ctx, cli)
+		}
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, cli)
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_ComposerJSONLookUp(t *testing.T) {
+	h := &ComposerJSON{}
+	as, err := h.LookUp("testdata/composer/composer.json")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "php")
+	assert.Equal(t, l.Version, "8.2.15")
+	assert.Equal(t, l.Line, 14)
+	assert.DeepEqual(t, describeModules(as), []string{"guzzlehttp/guzzle@^7.8", "laravel/framework@^10.0", "phpunit/phpunit@^10.5"})
+	assert.Equal(t, as[1].(*component.Module).Line, 7)
+	assert.Equal(t, as[3].(*component.Module).Line, 10)
+	assert.Equal(t, as[3].(*component.Module).Ecosystem, component.EcosystemPackagist)
+}
+
+func Test_ComposerLockLookUp(t *testing.T) {
+	h := &ComposerLock{Transitive: true}
+	as, err := h.LookUp("testdata/composer/composer.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"guzzlehttp/guzzle@7.8.1", "laravel/framework@10.43.0", "phpunit/phpunit@10.5.10", "~guzzlehttp/psr7@2.6.2",
+	})
+	guzzle := as[0].(*component.Module)
+	assert.Equal(t, guzzle.Line, 8)
+	assert.Equal(t, guzzle.GHOrg, "guzzle")
+	assert.Equal(t, guzzle.GHRepo, "guzzle")
+	assert.DeepEqual(t, as[3].(*component.Module).Path, []string{"guzzlehttp/guzzle"})
+}
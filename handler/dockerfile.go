@@ -3,17 +3,38 @@ package handler
 import (
 	"bufio"
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/izziiyt/compaa/component"
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
 )
 
+// Dockerfile reads base images of Dockerfiles. DockerConfig, if set, provides registry credentials.
 type Dockerfile struct {
-	HTTPClient *http.Client
+	HTTPClient   *http.Client
+	DockerConfig *dockerconfig.Config
 }
 
+// dockerInstruction is a logical line of a Dockerfile, with continuations joined.
+type dockerInstruction struct {
+	cmd   string
+	flags map[string]string
+	args  []string
+	line  int
+}
+
+var (
+	dockerDirective = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+	dockerHeredoc   = regexp.MustCompile(`<<-?([A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
 func (h *Dockerfile) LookUp(path string) (buf []component.Component, err error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -21,26 +42,279 @@ func (h *Dockerfile) LookUp(path string) (buf []component.Component, err error)
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
+	is, escape, err := parseDockerfile(f)
+	if err != nil {
+		return
+	}
+
+	// ARGs before the first FROM are only visible to FROM, or to stages which declare them again
+	global := map[string]string{}
+	var vars map[string]string
+	stages := map[string]bool{}
+	image := func(raw string, scope map[string]string, line int) component.Component {
+		ref, unset := expandDockerArgs(raw, scope, escape)
+		if len(unset) > 0 || ref == "" {
+			return &component.Image{
+				RawString: raw,
+				Line:      line,
+				Err:       fmt.Errorf("%w: ARG has no value", component.ErrSkip),
+			}
+		}
+		if stages[strings.ToLower(ref)] || ref == "scratch" {
+			return nil
+		}
+		c := &component.Image{Line: line}
+		return c.FromRawString(ref)
+	}
+
+	for _, in := range is {
+		switch in.cmd {
+		case "ARG":
+			scope := global
+			if vars != nil {
+				scope = vars
+			}
+			for _, a := range in.args {
+				name, value, ok := strings.Cut(a, "=")
+				if ok {
+					scope[name], _ = expandDockerArgs(value, scope, escape)
+				} else if v, ok := global[name]; ok && vars != nil {
+					scope[name] = v
+				}
+			}
+		case "FROM":
+			vars = map[string]string{}
+			if len(in.args) == 0 {
+				continue
+			}
+			if c := image(in.args[0], global, in.line); c != nil {
+				buf = append(buf, c)
+			}
+			if len(in.args) >= 3 && strings.EqualFold(in.args[1], "AS") {
+				stages[strings.ToLower(in.args[2])] = true
+			}
+		case "COPY":
+			from, ok := in.flags["from"]
+			if !ok {
+				continue
+			}
+			// --from=0 refers to a stage by index
+			if _, err := strconv.Atoi(from); err == nil {
+				continue
+			}
+			if c := image(from, vars, in.line); c != nil {
+				buf = append(buf, c)
+			}
+		}
+	}
+	return
+}
+
+// parseDockerfile splits a Dockerfile into instructions and returns the escape character set by its parser directive.
+func parseDockerfile(r io.Reader) (is []*dockerInstruction, escape rune, err error) {
+	escape = '\\'
+	directives := true
+	var heredocs []string
+	logical := &strings.Builder{}
+	start := 0
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "FROM") {
-			tokens := strings.Split(line, " ")
-			if len(tokens) < 2 {
+		if len(heredocs) > 0 {
+			if strings.TrimLeft(line, "\t") == heredocs[0] {
+				heredocs = heredocs[1:]
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if directives {
+			if m := dockerDirective.FindStringSubmatch(trimmed); m != nil {
+				if strings.EqualFold(m[1], "escape") && (m[2] == "\\" || m[2] == "`") {
+					escape = rune(m[2][0])
+				}
 				continue
 			}
-			c := &component.Image{}
-			c.FromRawString(tokens[1])
-			buf = append(buf, c)
+			directives = false
+		}
+		// comments and empty lines don't end a continued instruction
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if logical.Len() == 0 {
+			start = n
+		}
+		if body, ok := strings.CutSuffix(trimmed, string(escape)); ok {
+			logical.WriteString(body + " ")
+			continue
+		}
+		logical.WriteString(trimmed)
+		in := newDockerInstruction(logical.String(), start)
+		logical.Reset()
+		if in == nil {
+			continue
+		}
+		if in.cmd == "RUN" || in.cmd == "COPY" || in.cmd == "ADD" {
+			for _, a := range in.args {
+				if m := dockerHeredoc.FindStringSubmatch(a); m != nil {
+					heredocs = append(heredocs, m[1])
+				}
+			}
+		}
+		is = append(is, in)
+	}
+	// a continued instruction at the end of the file
+	if in := newDockerInstruction(logical.String(), start); in != nil {
+		is = append(is, in)
+	}
+	return is, escape, scanner.Err()
+}
+
+// newDockerInstruction returns nil if s has no words, e.g. for a lone escape line.
+func newDockerInstruction(s string, line int) *dockerInstruction {
+	words := splitDockerWords(s)
+	if len(words) == 0 {
+		return nil
+	}
+	in := &dockerInstruction{cmd: strings.ToUpper(words[0]), flags: map[string]string{}, line: line}
+	words = words[1:]
+	for len(words) > 0 && strings.HasPrefix(words[0], "--") {
+		name, value, _ := strings.Cut(words[0][2:], "=")
+		in.flags[strings.ToLower(name)] = value
+		words = words[1:]
+	}
+	in.args = words
+	return in
+}
+
+// splitDockerWords splits s on whitespace outside quotes and removes the quotes.
+func splitDockerWords(s string) (words []string) {
+	w := &strings.Builder{}
+	inWord := false
+	var quote rune
+	for _, c := range s {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			w.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case unicode.IsSpace(c):
+			if inWord {
+				words = append(words, w.String())
+				w.Reset()
+				inWord = false
+			}
+		default:
+			w.WriteRune(c)
+			inWord = true
 		}
 	}
+	if inWord {
+		words = append(words, w.String())
+	}
 	return
 }
 
+// expandDockerArgs substitutes $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:+alt} and ${VAR+alt} in s.
+// Variables referenced without a value or a default are returned as unset.
+func expandDockerArgs(s string, vars map[string]string, escape rune) (string, []string) {
+	out := &strings.Builder{}
+	var unset []string
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		if c == escape && i+1 < len(rs) && rs[i+1] == '$' {
+			out.WriteRune('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 == len(rs) {
+			out.WriteRune(c)
+			continue
+		}
+
+		if rs[i+1] != '{' {
+			j := i + 1
+			for j < len(rs) && isDockerVarRune(rs[j]) {
+				j++
+			}
+			if j == i+1 {
+				out.WriteRune(c)
+				continue
+			}
+			name := string(rs[i+1 : j])
+			if v, ok := vars[name]; ok {
+				out.WriteString(v)
+			} else {
+				unset = append(unset, name)
+			}
+			i = j - 1
+			continue
+		}
+
+		// find the matching brace, defaults may contain other substitutions
+		depth := 0
+		j := i + 1
+		for ; j < len(rs); j++ {
+			if rs[j] == '{' {
+				depth++
+			} else if rs[j] == '}' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if j == len(rs) {
+			out.WriteString(string(rs[i:]))
+			break
+		}
+		inner := string(rs[i+2 : j])
+		i = j
+
+		k := strings.IndexFunc(inner, func(r rune) bool { return !isDockerVarRune(r) })
+		if k < 0 {
+			k = len(inner)
+		}
+		name, op := inner[:k], inner[k:]
+		v, ok := vars[name]
+		var word string
+		var wordUnset []string
+		for _, p := range []string{":-", ":+", "-", "+"} {
+			if w, found := strings.CutPrefix(op, p); found {
+				op = p
+				word, wordUnset = expandDockerArgs(w, vars, escape)
+				break
+			}
+		}
+		switch {
+		case op == ":-" && (!ok || v == ""), op == "-" && !ok:
+			out.WriteString(word)
+			unset = append(unset, wordUnset...)
+		case op == ":+" && ok && v != "", op == "+" && ok:
+			out.WriteString(word)
+			unset = append(unset, wordUnset...)
+		case op == ":+", op == "+":
+		case ok:
+			out.WriteString(v)
+		case op == "":
+			unset = append(unset, name)
+		}
+	}
+	return out.String(), unset
+}
+
+func isDockerVarRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
 func (h *Dockerfile) SyncWithSource(c component.Component, ctx context.Context) component.Component {
 	switch v := c.(type) {
 	case *component.Image:
-		v = v.SyncWithRegistry(ctx, h.HTTPClient)
+		v = v.SyncWithRegistry(ctx, h.HTTPClient, h.DockerConfig)
+		v = v.SyncWithEndOfLife(ctx, h.HTTPClient)
 		return v
 	default:
 		return v
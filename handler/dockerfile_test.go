@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/izziiyt/compaa/component"
@@ -11,15 +13,72 @@ func Test_DockerfileLookUp(t *testing.T) {
 	h := &Dockerfile{}
 	as, err := h.LookUp("testdata/Dockerfile")
 	assert.NilError(t, err)
-	assert.Equal(t, len(as), 2)
+	assert.Equal(t, len(as), 4)
 
 	i0 := as[0].(*component.Image)
 	assert.Equal(t, i0.Registry, "docker.io")
 	assert.Equal(t, i0.Namespace, "library")
 	assert.Equal(t, i0.Repository, "golang")
+	assert.Equal(t, i0.Tag, "1.21.1-bullseye")
+	assert.Equal(t, i0.Line, 7)
 
 	i1 := as[1].(*component.Image)
 	assert.Equal(t, i1.Registry, "gcr.io")
 	assert.Equal(t, i1.Namespace, "distroless")
 	assert.Equal(t, i1.Repository, "base-nossl-debian11")
+	assert.Equal(t, i1.Line, 19)
+
+	i2 := as[2].(*component.Image)
+	assert.Equal(t, i2.Repository, "nginx")
+	assert.Equal(t, i2.Tag, "1.25")
+	assert.Equal(t, i2.Line, 26)
+
+	i3 := as[3].(*component.Image)
+	assert.Equal(t, i3.RawString, "${UNSET_IMAGE}")
+	assert.Assert(t, errors.Is(i3.Err, component.ErrSkip))
+}
+
+func Test_ParseDockerfileEscape(t *testing.T) {
+	is, escape, err := parseDockerfile(strings.NewReader("# escape=`\nFROM `\n  alpine:3.19 AS base\nRUN echo `$HOME\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, escape, '`')
+	assert.Equal(t, len(is), 2)
+	assert.Equal(t, is[0].cmd, "FROM")
+	assert.DeepEqual(t, is[0].args, []string{"alpine:3.19", "AS", "base"})
+	assert.Equal(t, is[0].line, 2)
+}
+
+func Test_ParseDockerfileLoneEscape(t *testing.T) {
+	for _, s := range []string{
+		"FROM alpine:3.19\n\\",
+		"FROM alpine:3.19\n\\\n# comment\n\n",
+		"\\\n\\\nFROM alpine:3.19\n",
+	} {
+		is, _, err := parseDockerfile(strings.NewReader(s))
+		assert.NilError(t, err)
+		assert.Equal(t, len(is), 1, s)
+		assert.Equal(t, is[0].cmd, "FROM")
+	}
+}
+
+func Test_ExpandDockerArgs(t *testing.T) {
+	vars := map[string]string{"A": "a", "EMPTY": ""}
+	for _, tt := range []struct {
+		in    string
+		want  string
+		unset []string
+	}{
+		{in: "$A/${A}", want: "a/a"},
+		{in: "${B:-b}", want: "b"},
+		{in: "${EMPTY:-e}", want: "e"},
+		{in: "${EMPTY-e}", want: ""},
+		{in: "${A:+x}${B:+y}", want: "x"},
+		{in: "${B:-${A}}", want: "a"},
+		{in: `\$A`, want: "$A"},
+		{in: "img:$B", want: "img:", unset: []string{"B"}},
+	} {
+		got, unset := expandDockerArgs(tt.in, vars, '\\')
+		assert.Equal(t, got, tt.want, tt.in)
+		assert.DeepEqual(t, unset, tt.unset)
+	}
 }
@@ -12,7 +12,7 @@ import (
 )
 
 var (
-	moduleRegexp   = regexp.MustCompile(`^\s*gem ['"]([^,'"]+)['"]`)
+	moduleRegexp   = regexp.MustCompile(`^\s*gem ['"]([^,'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
 	languageRegexp = regexp.MustCompile(`^\s*ruby ['"](.+)['"]`)
 )
 
@@ -29,18 +29,19 @@ func (h *GemFile) LookUp(path string) (buf []component.Component, err error) {
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
+	for n := 1; scanner.Scan(); n++ {
 		line := scanner.Text()
 		if match := languageRegexp.FindStringSubmatch(line); len(match) > 1 {
-			c := &component.Language{}
+			c := &component.Language{Line: n}
 			c.Name = "ruby"
 			c.Version = string(match[1])
 			buf = append(buf, c)
 			continue
 		}
 		if match := moduleRegexp.FindStringSubmatch(line); len(match) > 1 {
-			c := &component.Module{}
+			c := &component.Module{Ecosystem: component.EcosystemRubyGems, Line: n}
 			c.Name = string(match[1])
+			c.Version = string(match[2])
 			buf = append(buf, c)
 			continue
 		}
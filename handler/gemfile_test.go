@@ -17,6 +17,8 @@ func Test_GemfileLookUp(t *testing.T) {
 	assert.Equal(t, l.Version, "3.2.2")
 	m := as[1].(*component.Module)
 	assert.Equal(t, m.Name, "rails")
+	assert.Equal(t, m.Version, "~> 6.1.4")
 	m = as[len(as)-1].(*component.Module)
 	assert.Equal(t, m.Name, "spring")
+	assert.Equal(t, m.Line, 13)
 }
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// GemfileLock reads Gemfile.lock of Bundler. Gems of GIT sources on GitHub are checked against their repositories
+// instead of rubygems.org, gems of PATH sources are skipped.
+type GemfileLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+var (
+	// "    rack (2.2.8)" of specs, or "      rack (>= 2.0)" of dependencies
+	gemSpecRegexp = regexp.MustCompile(`^([^\s(!]+)(?: \(([^)]*)\))?(!)?$`)
+	// "ruby 3.2.2p53"
+	rubyVersionRegexp = regexp.MustCompile(`^ruby (\d+(?:\.\d+)*)`)
+)
+
+// gemSource is a GIT, PATH or GEM section of a lockfile.
+type gemSource struct {
+	kind   string
+	remote string
+}
+
+func (h *GemfileLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	l, g, err := parseGemfileLock(b)
+	if err != nil {
+		return
+	}
+	if l != nil {
+		buf = append(buf, l)
+	}
+	return append(buf, g.modules(h.Transitive)...), nil
+}
+
+func parseGemfileLock(b []byte) (*component.Language, *lockGraph, error) {
+	g := newLockGraph(component.EcosystemRubyGems)
+	var (
+		ruby    *component.Language
+		section string
+		source  *gemSource
+		spec    *lockPackage
+		// the gemspec of the project itself, whose dependencies are direct dependencies
+		project *lockPackage
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			section = trimmed
+			source = nil
+			if section == "GIT" || section == "PATH" || section == "GEM" || section == "PLUGIN SOURCE" {
+				source = &gemSource{kind: section}
+			}
+			continue
+		}
+
+		switch {
+		case source != nil && indent == 2:
+			if v, ok := strings.CutPrefix(trimmed, "remote: "); ok {
+				source.remote = v
+			}
+		case source != nil && indent == 4:
+			m := gemSpecRegexp.FindStringSubmatch(trimmed)
+			if m == nil {
+				return nil, nil, fmt.Errorf("line %v: unexpected spec %q", n, trimmed)
+			}
+			// platform specific gems like "nokogiri (1.15.4-x86_64-linux)" share the version of the ruby platform
+			version, _, _ := strings.Cut(m[2], "-")
+			spec = &lockPackage{name: m[1], version: version, line: n}
+			if source.kind == "PATH" && source.remote == "." {
+				project = spec
+				continue
+			}
+			if _, ok := g.pkgs[spec.name]; ok {
+				// another platform of a gem already seen, its dependencies are the same
+				spec = nil
+				continue
+			}
+			spec.ghOrg, spec.ghRepo, spec.err = gemSourceRepository(source)
+			g.pkgs[spec.name] = spec
+		case source != nil && indent == 6 && spec != nil:
+			if m := gemSpecRegexp.FindStringSubmatch(trimmed); m != nil {
+				spec.deps = append(spec.deps, m[1])
+			}
+		case section == "DEPENDENCIES" && indent == 2:
+			if m := gemSpecRegexp.FindStringSubmatch(trimmed); m != nil {
+				g.roots = append(g.roots, m[1])
+			}
+		case section == "RUBY VERSION":
+			if m := rubyVersionRegexp.FindStringSubmatch(trimmed); m != nil {
+				ruby = &component.Language{Name: "ruby", Version: m[1], Line: n}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if project != nil {
+		// gemspec dependencies come first, as the gemspec line is usually at the top of Gemfile
+		g.roots = append(project.deps, g.roots...)
+	}
+	return ruby, g, nil
+}
+
+// gemSourceRepository returns the GitHub repository of a GIT source. Gems of other GIT and PATH sources are skipped.
+func gemSourceRepository(s *gemSource) (org, repo string, err error) {
+	switch s.kind {
+	case "GIT":
+		if m := component.GitHubRepositoryRegexp.FindStringSubmatch(s.remote); m != nil {
+			return m[1], m[2], nil
+		}
+		return "", "", fmt.Errorf("%w: installed from git %v", component.ErrSkip, s.remote)
+	case "PATH":
+		return "", "", fmt.Errorf("%w: installed from path %v", component.ErrSkip, s.remote)
+	}
+	return "", "", nil
+}
+
+func (h *GemfileLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		if v.GHOrg == "" {
+			v = v.SyncWithRubyGem(ctx, h.HTTPClient)
+		}
+		v = v.SyncWithGitHub(ctx, h.GCli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, h.HTTPClient)
+		return v
+	default:
+		return v
+	}
+}
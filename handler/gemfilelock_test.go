@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_GemfileLockLookUp(t *testing.T) {
+	h := &GemfileLock{}
+	as, err := h.LookUp("testdata/Gemfile.lock")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "ruby")
+	assert.Equal(t, l.Version, "3.3.0")
+	assert.Equal(t, l.Line, 55)
+	assert.DeepEqual(t, describeModules(as), []string{"kaminari@1.2.2", "local_gem@0.0.1!", "nokogiri@1.16.2", "private_gem@0.3.0!", "rails@7.2.0.alpha"})
+
+	rails := as[5].(*component.Module)
+	assert.Equal(t, rails.GHOrg, "rails")
+	assert.Equal(t, rails.GHRepo, "rails")
+	assert.Equal(t, rails.Line, 6)
+	assert.Equal(t, rails.Ecosystem, component.EcosystemRubyGems)
+
+	h.Transitive = true
+	as, err = h.LookUp("testdata/Gemfile.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"kaminari@1.2.2", "local_gem@0.0.1!", "nokogiri@1.16.2", "private_gem@0.3.0!", "rails@7.2.0.alpha",
+		"~activesupport@7.1.3", "~racc@1.7.3", "~actionpack@7.2.0.alpha", "~railties@7.2.0.alpha", "~rack@3.0.9",
+	})
+	assert.DeepEqual(t, as[10].(*component.Module).Path, []string{"rails", "actionpack"})
+}
+
+func Test_GitHubRemoteRegexp(t *testing.T) {
+	for _, remote := range []string{
+		"https://github.com/rails/rails.git",
+		"https://github.com/rails/rails",
+		"git@github.com:rails/rails.git",
+		"https://github.com/rails/rails.git/",
+		"git+https://github.com/rails/rails?branch=main#0123abc",
+	} {
+		m := component.GitHubRepositoryRegexp.FindStringSubmatch(remote)
+		assert.DeepEqual(t, m[1:], []string{"rails", "rails"})
+	}
+}
@@ -1,21 +1,30 @@
 package handler
 
 import (
+	"bufio"
 	"context"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/izziiyt/compaa/component"
 	"github.com/izziiyt/compaa/sdk/gopkg"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
+// GoMod reads go.mod. With Transitive, indirect requirements and modules of the sibling go.sum are included.
 type GoMod struct {
 	GCli       *github.Client
 	HTTPClient *http.Client
+	Transitive bool
 }
 
 func (h *GoMod) LookUp(path string) (buf []component.Component, err error) {
@@ -38,22 +47,107 @@ func (h *GoMod) LookUp(path string) (buf []component.Component, err error) {
 	t := &component.Language{
 		Name:    "go",
 		Version: pf.Go.Version,
+		Line:    pf.Go.Syntax.Start.Line,
 	}
 	buf = append(buf, t)
 
+	excluded := map[module.Version]bool{}
+	for _, e := range pf.Exclude {
+		excluded[e.Mod] = true
+	}
+
+	required := map[string]bool{}
 	for _, r := range pf.Require {
-		if r.Indirect {
+		required[r.Mod.Path] = true
+		if r.Indirect && !h.Transitive || excluded[r.Mod] {
 			continue
 		}
+		buf = append(buf, goModule(pf, r.Mod, r.Syntax.Start.Line, r.Indirect))
+	}
+	if !h.Transitive {
+		return
+	}
 
-		t := &component.Module{
-			Name: r.Mod.Path,
+	// go.mod files before go 1.17 don't list every module of the build, go.sum does
+	sums, err := readGoSum(filepath.Join(filepath.Dir(path), "go.sum"), excluded)
+	if err != nil {
+		return
+	}
+	for _, m := range sums {
+		if required[m.Path] {
+			continue
 		}
+		buf = append(buf, goModule(pf, m, 0, true))
+	}
+	return
+}
 
-		buf = append(buf, t)
+// goModule returns the module checked for mod, which is its replacement if pf replaces it.
+func goModule(pf *modfile.File, mod module.Version, line int, indirect bool) *component.Module {
+	m := &component.Module{
+		Ecosystem: component.EcosystemGo,
+		Name:      mod.Path,
+		Version:   mod.Version,
+		Line:      line,
+		Indirect:  indirect,
 	}
 
-	return
+	var rep *modfile.Replace
+	for _, r := range pf.Replace {
+		if r.Old.Path != mod.Path {
+			continue
+		}
+		// a replacement of the exact version wins over one of all versions
+		if r.Old.Version == mod.Version || r.Old.Version == "" && rep == nil {
+			rep = r
+		}
+	}
+	if rep == nil {
+		return m
+	}
+	if modfile.IsDirectoryPath(rep.New.Path) {
+		m.Err = fmt.Errorf("%w: replaced by local path %v", component.ErrSkip, rep.New.Path)
+		return m
+	}
+	m.Name = rep.New.Path
+	m.Version = rep.New.Version
+	m.Line = rep.Syntax.Start.Line
+	return m
+}
+
+// readGoSum returns the highest version, which isn't excluded, of each module whose content is hashed in go.sum.
+// Modules with only a /go.mod hash take part in version selection but aren't built, so they are left out.
+// A missing go.sum is not an error.
+func readGoSum(path string, excluded map[module.Version]bool) ([]module.Version, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	versions := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") || excluded[module.Version{Path: fields[0], Version: fields[1]}] {
+			continue
+		}
+		if v, ok := versions[fields[0]]; !ok || semver.Compare(fields[1], v) > 0 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var ms []module.Version
+	for _, p := range slices.Sorted(maps.Keys(versions)) {
+		ms = append(ms, module.Version{Path: p, Version: versions[p]})
+	}
+	return ms, nil
 }
 
 func (h *GoMod) SyncWithSource(c component.Component, ctx context.Context) component.Component {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/izziiyt/compaa/component"
@@ -15,9 +16,12 @@ func Test_GoModLookUp(t *testing.T) {
 
 	l := as[0].(*component.Language)
 	assert.Equal(t, l.Name, "go")
+	assert.Equal(t, l.Line, 3)
 
 	m0 := as[1].(*component.Module)
 	assert.Equal(t, m0.Name, "github.com/sample/example")
+	assert.Equal(t, m0.Line, 6)
+	assert.Equal(t, m0.Version, "v0.17.45")
 	m1 := as[2].(*component.Module)
 	assert.Equal(t, m1.Name, "go.uber.org/zap")
 	m2 := as[3].(*component.Module)
@@ -25,3 +29,31 @@ func Test_GoModLookUp(t *testing.T) {
 	m3 := as[4].(*component.Module)
 	assert.Equal(t, m3.Name, "gopkg.in/go-playground/validator.v8")
 }
+
+func Test_GoModLookUpTransitive(t *testing.T) {
+	h := &GoMod{Transitive: true}
+	as, err := h.LookUp("testdata/gomod")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 10)
+
+	rep := as[5].(*component.Module)
+	assert.Equal(t, rep.Name, "github.com/go-gorm/gorm")
+	assert.Equal(t, rep.Version, "v1.25.6")
+	assert.Equal(t, rep.Line, 19)
+	local := as[6].(*component.Module)
+	assert.Equal(t, local.Name, "gotest.tools")
+	assert.Assert(t, errors.Is(local.Err, component.ErrSkip))
+
+	ind := as[7].(*component.Module)
+	assert.Equal(t, ind.Name, "sample.io/example")
+	assert.Assert(t, ind.Indirect)
+	assert.Equal(t, ind.Line, 15)
+
+	// go.sum modules, where the excluded version of github.com/pkg/errors is passed over
+	spew := as[8].(*component.Module)
+	assert.Equal(t, spew.Name, "github.com/davecgh/go-spew")
+	assert.Assert(t, spew.Indirect)
+	pkgErrors := as[9].(*component.Module)
+	assert.Equal(t, pkgErrors.Name, "github.com/pkg/errors")
+	assert.Equal(t, pkgErrors.Version, "v0.8.1")
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// BuildGradle reads the dependencies of build.gradle and build.gradle.kts declared with coordinates,
+// like implementation("group:name:version") or implementation group: 'group', name: 'name', version: 'version'.
+// Dependencies of version catalogs are reported by VersionCatalog. The Java toolchain is checked as a runtime.
+type BuildGradle struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+var (
+	// implementation 'g:a:v', testImplementation("g:a:v"), api(platform("g:a:v")) or classpath "g:a"
+	gradleCoordinateRegexp = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*(?:(?:enforcedPlatform|platform)\s*\(\s*)?['"]([\w.-]+):([\w.-]+)(?::((?:\$\{[^}]*\}|[^'"@:\s])+))?(?::[\w.-]+)?(?:@\w+)?['"]`)
+	// implementation group: 'g', name: 'a', version: 'v'
+	gradleMapRegexp = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*group\s*[:=]\s*['"]([^'"]+)['"]\s*,\s*name\s*[:=]\s*['"]([^'"]+)['"](?:\s*,\s*version\s*[:=]\s*['"]([^'"]+)['"])?`)
+	// springVersion = '6.1.3', val springVersion = "6.1.3" or set("springVersion", "6.1.3")
+	gradleVariableRegexp  = regexp.MustCompile(`^\s*(?:ext\.|def\s+|val\s+|var\s+)?(\w+)\s*=\s*['"]([^'"$]+)['"]`)
+	gradleSetRegexp       = regexp.MustCompile(`set\(\s*['"](\w+)['"]\s*,\s*['"]([^'"$]+)['"]\s*\)`)
+	gradleReferenceRegexp = regexp.MustCompile(`\$\{?(?:project\.|rootProject\.)?(?:property\(['"])?(\w+)(?:['"]\))?\}?`)
+	// languageVersion = JavaLanguageVersion.of(17) or languageVersion.set(JavaLanguageVersion.of(17))
+	gradleToolchainRegexp = regexp.MustCompile(`JavaLanguageVersion\.of\(\s*['"]?(\d+)['"]?\s*\)`)
+	// sourceCompatibility = JavaVersion.VERSION_17 or sourceCompatibility = '1.8'
+	gradleCompatibilityRegexp = regexp.MustCompile(`(?:sourceCompatibility|targetCompatibility)\s*=\s*(?:JavaVersion\.VERSION_([\d_]+)|['"]([\d.]+)['"])`)
+)
+
+// gradleConfigurationRegexp matches the configurations whose dependencies are reported; other method calls taking a string aren't dependencies.
+var gradleConfigurationRegexp = regexp.MustCompile(`^(?:\w*(?:[Ii]mplementation|[Aa]pi|[Cc]ompileOnly|[Rr]untimeOnly|[Cc]ompile|[Rr]untime|[Aa]nnotationProcessor)|kapt|ksp|classpath|developmentOnly)$`)
+
+func (h *BuildGradle) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	// gradle.properties of the project and of the build root may define versions
+	vars := map[string]string{}
+	for _, dir := range []string{filepath.Dir(filepath.Dir(path)), filepath.Dir(path)} {
+		if pb, err := os.ReadFile(filepath.Join(dir, "gradle.properties")); err == nil {
+			for _, l := range strings.Split(string(pb), "\n") {
+				if k, v, ok := strings.Cut(l, "="); ok && !strings.HasPrefix(strings.TrimSpace(l), "#") {
+					vars[strings.TrimSpace(k)] = strings.TrimSpace(v)
+				}
+			}
+		}
+	}
+	for _, l := range strings.Split(string(b), "\n") {
+		if m := gradleVariableRegexp.FindStringSubmatch(l); m != nil {
+			vars[m[1]] = m[2]
+		}
+		for _, m := range gradleSetRegexp.FindAllStringSubmatch(l, -1) {
+			vars[m[1]] = m[2]
+		}
+	}
+	resolve := func(s string) string {
+		return gradleReferenceRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+			if v, ok := vars[gradleReferenceRegexp.FindStringSubmatch(ref)[1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	var java *component.Language
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		if m := gradleToolchainRegexp.FindStringSubmatch(line); m != nil {
+			// the toolchain takes precedence over compatibility
+			java = &component.Language{Name: "java", Version: m[1], Line: n}
+		} else if m := gradleCompatibilityRegexp.FindStringSubmatch(line); m != nil && java == nil {
+			v := strings.ReplaceAll(m[1], "_", ".") + m[2]
+			java = &component.Language{Name: "java", Version: javaRelease(v), Line: n}
+		}
+
+		m := gradleCoordinateRegexp.FindStringSubmatch(line)
+		if m == nil {
+			m = gradleMapRegexp.FindStringSubmatch(line)
+		}
+		if m == nil || !gradleConfigurationRegexp.MatchString(m[1]) {
+			continue
+		}
+		name := m[2] + ":" + m[3]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		buf = append(buf, &component.Module{Ecosystem: component.EcosystemMaven, Name: name, Version: resolve(m[4]), Line: n})
+	}
+	if java != nil {
+		buf = append([]component.Component{java}, buf...)
+	}
+	return buf, scanner.Err()
+}
+
+func (h *BuildGradle) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithMaven(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// VersionCatalog reads the libraries of Gradle version catalogs like gradle/libs.versions.toml.
+type VersionCatalog struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+func (h *VersionCatalog) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	catalog := struct {
+		Versions  map[string]any `toml:"versions"`
+		Libraries map[string]any `toml:"libraries"`
+	}{}
+	if _, err = toml.Decode(string(b), &catalog); err != nil {
+		return
+	}
+
+	for _, alias := range slices.Sorted(maps.Keys(catalog.Libraries)) {
+		m := &component.Module{Ecosystem: component.EcosystemMaven, Line: tomlKeyLine(b, "libraries", alias)}
+		switch lib := catalog.Libraries[alias].(type) {
+		case string:
+			// "group:name:version"
+			parts := strings.SplitN(lib, ":", 3)
+			if len(parts) < 2 {
+				continue
+			}
+			m.Name = parts[0] + ":" + parts[1]
+			if len(parts) == 3 {
+				m.Version = parts[2]
+			}
+		case map[string]any:
+			if module, ok := lib["module"].(string); ok {
+				m.Name = module
+			} else {
+				group, _ := lib["group"].(string)
+				name, _ := lib["name"].(string)
+				m.Name = group + ":" + name
+			}
+			m.Version = catalogVersion(lib["version"], catalog.Versions)
+		default:
+			continue
+		}
+		buf = append(buf, m)
+	}
+	return
+}
+
+// catalogVersion resolves a version of a library, "1.0", {ref = "spring"} or {strictly = "1.0", prefer = "1.0.1"}.
+func catalogVersion(v any, versions map[string]any) string {
+	switch d := v.(type) {
+	case string:
+		return d
+	case map[string]any:
+		if ref, ok := d["ref"].(string); ok {
+			return catalogVersion(versions[ref], nil)
+		}
+		for _, k := range []string{"strictly", "require", "prefer"} {
+			if s, ok := d[k].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func (h *VersionCatalog) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithMaven(c, ctx, h.HTTPClient, h.GCli)
+}
@@ -2,11 +2,10 @@ package handler
 
 import (
 	"context"
-	"fmt"
 	"sync"
 
-	"github.com/fatih/color"
 	"github.com/izziiyt/compaa/component"
+	"github.com/izziiyt/compaa/report"
 )
 
 type Handler interface {
@@ -14,20 +13,30 @@ type Handler interface {
 	SyncWithSource(c component.Component, ctx context.Context) component.Component
 }
 
-func Handle(h Handler, ctx context.Context, path string, wc *component.WarnCondition) {
-	fmt.Printf("%v\n", path)
+func Handle(h Handler, ctx context.Context, path string, p *component.Policy) *report.Manifest {
+	m := &report.Manifest{
+		Path:       path,
+		Components: []*component.Result{},
+	}
 
 	cs, err := h.LookUp(path)
 	if err != nil {
-		color.Red("├ LookUp error: %v\n", err)
+		m.Error = err.Error()
 	}
 
+	Sync(h, ctx, cs)
+	for _, c := range cs {
+		m.Components = append(m.Components, Evaluate(c, path, p))
+	}
+	return m
+}
+
+// Sync fetches the state of components from their sources concurrently.
+func Sync(h Handler, ctx context.Context, cs []component.Component) {
 	wg := &sync.WaitGroup{}
 	done := make(chan struct{}, 10)
-	logger := &component.DefaultLogger{} // Create a DefaultLogger instance
 	for _, c := range cs {
 		if ok := c.LoadCache(); ok {
-			c.Logging(wc, logger) // Pass the logger
 			continue
 		}
 		wg.Add(1)
@@ -35,10 +44,16 @@ func Handle(h Handler, ctx context.Context, path string, wc *component.WarnCondi
 			done <- struct{}{}
 			c = h.SyncWithSource(c, ctx)
 			c.StoreCache()
-			c.Logging(wc, logger) // Pass the logger
 			<-done
 			wg.Done()
 		}(ctx, c)
 	}
 	wg.Wait()
 }
+
+// Evaluate evaluates a synced component against the policy declared for the manifest at path.
+func Evaluate(c component.Component, path string, p *component.Policy) *component.Result {
+	r := c.Evaluate(p)
+	p.Suppress(path, r)
+	return r
+}
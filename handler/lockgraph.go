@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// lockPackage is a resolved package of a lockfile. deps are keys of other packages in the graph.
+// ghOrg and ghRepo are set for packages the lockfile resolves from GitHub instead of a registry.
+type lockPackage struct {
+	name    string
+	version string
+	line    int
+	deps    []string
+	err     error
+	ghOrg   string
+	ghRepo  string
+}
+
+// lockGraph is the resolved dependency tree of a lockfile. roots are keys of the direct dependencies.
+type lockGraph struct {
+	ecosystem string
+	pkgs      map[string]*lockPackage
+	roots     []string
+}
+
+func newLockGraph(ecosystem string) *lockGraph {
+	return &lockGraph{ecosystem: ecosystem, pkgs: map[string]*lockPackage{}}
+}
+
+// modules walks the graph breadth first, so each module carries its shortest dependency path.
+// A package name is reported once even if several versions are locked.
+func (g *lockGraph) modules(transitive bool) (buf []component.Component) {
+	type node struct {
+		key  string
+		path []string
+	}
+	seen := map[string]bool{}
+	names := map[string]bool{}
+	var queue []node
+	for _, k := range g.roots {
+		queue = append(queue, node{key: k})
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		p, ok := g.pkgs[n.key]
+		if !ok || seen[n.key] {
+			continue
+		}
+		seen[n.key] = true
+		if !names[p.name] {
+			names[p.name] = true
+			buf = append(buf, &component.Module{
+				Ecosystem: g.ecosystem,
+				Name:      p.name,
+				Version:   p.version,
+				Line:      p.line,
+				Indirect:  len(n.path) > 0,
+				Path:      n.path,
+				Err:       p.err,
+				GHOrg:     p.ghOrg,
+				GHRepo:    p.ghRepo,
+			})
+		}
+		if !transitive {
+			continue
+		}
+		path := append(n.path[:len(n.path):len(n.path)], p.name)
+		for _, d := range p.deps {
+			queue = append(queue, node{key: d, path: path})
+		}
+	}
+	return
+}
+
+// orphanRoots takes the packages nothing depends on as the direct dependencies, for lockfiles which don't tell them.
+func (g *lockGraph) orphanRoots() {
+	required := map[string]bool{}
+	for _, p := range g.pkgs {
+		for _, d := range p.deps {
+			required[d] = true
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(g.pkgs)) {
+		if !required[k] {
+			g.roots = append(g.roots, k)
+		}
+	}
+}
+
+func syncWithNPM(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		v = v.SyncWithNPM(ctx, cli)
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	default:
+		return v
+	}
+}
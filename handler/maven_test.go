@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_PomXMLLookUp(t *testing.T) {
+	h := &PomXML{}
+	as, err := h.LookUp("testdata/maven/app/pom.xml")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "java")
+	assert.Equal(t, l.Version, "21")
+	assert.Equal(t, l.Line, 14)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"com.example:sample-parent@1.0.0",
+		"com.google.guava:guava@33.0.0-jre",
+		"com.example:common@1.0.0",
+		"com.fasterxml.jackson.core:jackson-databind@",
+		"com.fasterxml.jackson:jackson-bom@2.16.1",
+	})
+	assert.Equal(t, as[1].(*component.Module).Line, 5)
+	assert.Equal(t, as[2].(*component.Module).Line, 31)
+	assert.Equal(t, as[5].(*component.Module).Ecosystem, component.EcosystemMaven)
+
+	as, err = h.LookUp("testdata/maven/pom.xml")
+	assert.NilError(t, err)
+	assert.Equal(t, as[0].(*component.Language).Version, "17")
+	assert.DeepEqual(t, describeModules(as), []string{
+		"org.springframework.boot:spring-boot-starter-parent@3.2.2",
+		"com.google.guava:guava@33.0.0-jre",
+	})
+}
+
+func Test_BuildGradleLookUp(t *testing.T) {
+	h := &BuildGradle{}
+	as, err := h.LookUp("testdata/gradle/app/build.gradle.kts")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Version, "21")
+	assert.Equal(t, l.Line, 9)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"org.springframework.boot:spring-boot-dependencies@3.2.2",
+		"org.springframework.boot:spring-boot-starter-web@",
+		"com.fasterxml.jackson.core:jackson-databind@2.16.1",
+		"org.jetbrains.kotlinx:kotlinx-coroutines-core@1.7.3",
+		"org.junit.jupiter:junit-jupiter@5.10.1",
+	})
+	assert.Equal(t, as[5].(*component.Module).Line, 20)
+
+	as, err = h.LookUp("testdata/gradle/app/build.gradle")
+	assert.NilError(t, err)
+	assert.Equal(t, as[0].(*component.Language).Version, "8")
+	assert.DeepEqual(t, describeModules(as), []string{
+		"org.projectlombok:lombok@1.18.30",
+		"commons-io:commons-io@2.15.1",
+	})
+}
+
+func Test_VersionCatalogLookUp(t *testing.T) {
+	h := &VersionCatalog{}
+	as, err := h.LookUp("testdata/gradle/gradle/libs.versions.toml")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"com.google.guava:guava@33.0.0-jre",
+		"org.junit.jupiter:junit-jupiter@5.10.1",
+		"org.springframework.boot:spring-boot-starter@3.2.2",
+	})
+	assert.Equal(t, as[2].(*component.Module).Line, 5)
+}
@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// CSProj reads the PackageReference items of .csproj, .fsproj and .vbproj. Versions of central package management are
+// taken from the closest Directory.Packages.props. TargetFramework and TargetFrameworks are checked as the dotnet runtime.
+type CSProj struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+// msbuildItem is a PackageReference, PackageVersion or GlobalPackageReference.
+type msbuildItem struct {
+	kind            string
+	name            string
+	version         string
+	versionOverride string
+	line            int
+}
+
+type msbuildProject struct {
+	properties    map[string]string
+	propertyLines map[string]int
+	items         []*msbuildItem
+}
+
+var (
+	msbuildPropertyRegexp = regexp.MustCompile(`\$\(([\w.-]+)\)`)
+	// net8.0, net8.0-windows or netcoreapp3.1; netstandard and .NET Framework like net48 aren't runtimes with a lifecycle of dotnet
+	dotnetFrameworkRegexp = regexp.MustCompile(`^(?:net|netcoreapp)(\d+\.\d+)(?:-[\w.]+)?$`)
+)
+
+func parseMSBuild(b []byte) (*msbuildProject, error) {
+	p := &msbuildProject{properties: map[string]string{}, propertyLines: map[string]int{}}
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var stack []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch se := tok.(type) {
+		case xml.StartElement:
+			line, _ := d.InputPos()
+			switch {
+			case len(stack) > 0 && stack[len(stack)-1] == "PropertyGroup":
+				var v string
+				if err := d.DecodeElement(&v, &se); err != nil {
+					return nil, err
+				}
+				// later definitions win, as in MSBuild
+				p.properties[se.Name.Local] = strings.TrimSpace(v)
+				p.propertyLines[se.Name.Local] = line
+			case se.Name.Local == "PackageReference" || se.Name.Local == "PackageVersion" || se.Name.Local == "GlobalPackageReference":
+				item := &msbuildItem{kind: se.Name.Local, line: line}
+				for _, a := range se.Attr {
+					switch a.Name.Local {
+					case "Include":
+						item.name = a.Value
+					case "Version":
+						item.version = a.Value
+					case "VersionOverride":
+						item.versionOverride = a.Value
+					}
+				}
+				// versions may be child elements too
+				children := struct {
+					Version         string `xml:"Version"`
+					VersionOverride string `xml:"VersionOverride"`
+				}{}
+				if err := d.DecodeElement(&children, &se); err != nil {
+					return nil, err
+				}
+				if item.version == "" {
+					item.version = strings.TrimSpace(children.Version)
+				}
+				if item.versionOverride == "" {
+					item.versionOverride = strings.TrimSpace(children.VersionOverride)
+				}
+				// items with Update or Remove instead of Include modify items declared elsewhere
+				if item.name != "" {
+					p.items = append(p.items, item)
+				}
+			default:
+				stack = append(stack, se.Name.Local)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return p, nil
+}
+
+// interpolate replaces $(...) with properties of the project. Unknown references are left as they are.
+func (p *msbuildProject) interpolate(s string) string {
+	for i := 0; i < 10 && strings.Contains(s, "$("); i++ {
+		replaced := msbuildPropertyRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+			if v, ok := p.properties[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+		if replaced == s {
+			break
+		}
+		s = replaced
+	}
+	return s
+}
+
+// centralPackageVersions reads the PackageVersion items of the closest Directory.Packages.props in dir or above.
+// Keys are lowercased, as package ids are case insensitive.
+func centralPackageVersions(dir string) map[string]string {
+	versions := map[string]string{}
+	for {
+		if b, err := os.ReadFile(filepath.Join(dir, "Directory.Packages.props")); err == nil {
+			if p, err := parseMSBuild(b); err == nil {
+				for _, item := range p.items {
+					if item.kind == "PackageVersion" {
+						versions[strings.ToLower(item.name)] = p.interpolate(item.version)
+					}
+				}
+			}
+			return versions
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return versions
+		}
+		dir = parent
+	}
+}
+
+func (h *CSProj) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	p, err := parseMSBuild(b)
+	if err != nil {
+		return
+	}
+
+	frameworks := map[string]bool{}
+	for _, k := range []string{"TargetFramework", "TargetFrameworks"} {
+		for _, tfm := range strings.Split(p.interpolate(p.properties[k]), ";") {
+			m := dotnetFrameworkRegexp.FindStringSubmatch(strings.TrimSpace(tfm))
+			if m == nil || frameworks[m[1]] {
+				continue
+			}
+			frameworks[m[1]] = true
+			buf = append(buf, &component.Language{Name: "dotnet", Version: m[1], Line: p.propertyLines[k]})
+		}
+	}
+
+	seen := map[string]bool{}
+	var central map[string]string
+	for _, item := range p.items {
+		if item.kind != "PackageReference" || seen[strings.ToLower(item.name)] {
+			continue
+		}
+		seen[strings.ToLower(item.name)] = true
+		version := item.versionOverride
+		if version == "" {
+			version = item.version
+		}
+		if version == "" {
+			if central == nil {
+				central = centralPackageVersions(filepath.Dir(path))
+			}
+			version = central[strings.ToLower(item.name)]
+		}
+		buf = append(buf, &component.Module{
+			Ecosystem: component.EcosystemNuGet,
+			Name:      item.name,
+			Version:   p.interpolate(version),
+			Line:      item.line,
+		})
+	}
+	return
+}
+
+func (h *CSProj) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNuGet(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// DirectoryPackagesProps reads the PackageVersion and GlobalPackageReference items of Directory.Packages.props.
+type DirectoryPackagesProps struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+func (h *DirectoryPackagesProps) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	p, err := parseMSBuild(b)
+	if err != nil {
+		return
+	}
+	seen := map[string]bool{}
+	for _, item := range p.items {
+		if item.kind == "PackageReference" || seen[strings.ToLower(item.name)] {
+			continue
+		}
+		seen[strings.ToLower(item.name)] = true
+		buf = append(buf, &component.Module{
+			Ecosystem: component.EcosystemNuGet,
+			Name:      item.name,
+			Version:   p.interpolate(item.version),
+			Line:      item.line,
+		})
+	}
+	return
+}
+
+func (h *DirectoryPackagesProps) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNuGet(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// PackagesLockJSON reads packages.lock.json of NuGet. Packages of every target framework are reported,
+// and project references are skipped.
+type PackagesLockJSON struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+func (h *PackagesLockJSON) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parsePackagesLockJSON(b)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parsePackagesLockJSON(b []byte) (*lockGraph, error) {
+	lock := struct {
+		// target frameworks, like "net8.0" or "net8.0/linux-x64", to packages
+		Dependencies map[string]map[string]struct {
+			Type         string            `json:"type"`
+			Resolved     string            `json:"resolved"`
+			Dependencies map[string]string `json:"dependencies"`
+		} `json:"dependencies"`
+	}{}
+	if err := json.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+	// a name also appears in dependencies of other packages, but only packages open an object
+	lines := strings.Split(string(b), "\n")
+	nameLine := func(name string) int {
+		for i, l := range lines {
+			if strings.Contains(l, strconv.Quote(name)+": {") {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	g := newLockGraph(component.EcosystemNuGet)
+	for _, tfm := range slices.Sorted(maps.Keys(lock.Dependencies)) {
+		pkgs := lock.Dependencies[tfm]
+		// package ids are case insensitive
+		key := func(name string) string {
+			return tfm + "/" + strings.ToLower(name)
+		}
+		for _, name := range slices.Sorted(maps.Keys(pkgs)) {
+			p := pkgs[name]
+			lp := &lockPackage{name: name, version: p.Resolved, line: nameLine(name)}
+			if p.Type == "Project" {
+				lp.err = fmt.Errorf("%w: project reference", component.ErrSkip)
+			}
+			for _, d := range slices.Sorted(maps.Keys(p.Dependencies)) {
+				lp.deps = append(lp.deps, key(d))
+			}
+			g.pkgs[key(name)] = lp
+			if p.Type == "Direct" || p.Type == "Project" {
+				g.roots = append(g.roots, key(name))
+			}
+		}
+	}
+	return g, nil
+}
+
+func (h *PackagesLockJSON) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNuGet(c, ctx, h.HTTPClient, h.GCli)
+}
+
+func syncWithNuGet(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		v = v.SyncWithNuGet(ctx, cli)
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, cli)
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_CSProjLookUp(t *testing.T) {
+	h := &CSProj{}
+	as, err := h.LookUp("testdata/nuget/App/App.csproj")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 5)
+	for i, v := range []string{"8.0", "6.0"} {
+		l := as[i].(*component.Language)
+		assert.Equal(t, l.Name, "dotnet")
+		assert.Equal(t, l.Version, v)
+		assert.Equal(t, l.Line, 5)
+	}
+	assert.DeepEqual(t, describeModules(as), []string{"Newtonsoft.Json@13.0.3", "Serilog@3.0.1", "Polly@8.2.1"})
+	assert.Equal(t, as[2].(*component.Module).Line, 9)
+	assert.Equal(t, as[4].(*component.Module).Line, 11)
+	assert.Equal(t, as[4].(*component.Module).Ecosystem, component.EcosystemNuGet)
+}
+
+func Test_DirectoryPackagesPropsLookUp(t *testing.T) {
+	h := &DirectoryPackagesProps{}
+	as, err := h.LookUp("testdata/nuget/Directory.Packages.props")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"Newtonsoft.Json@13.0.3", "Serilog@3.1.1", "xunit@2.6.6", "Nerdbank.GitVersioning@3.6.133",
+	})
+	assert.Equal(t, as[3].(*component.Module).Line, 12)
+}
+
+func Test_PackagesLockJSONLookUp(t *testing.T) {
+	h := &PackagesLockJSON{Transitive: true}
+	as, err := h.LookUp("testdata/nuget/App/packages.lock.json")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{"Newtonsoft.Json@13.0.3", "Polly@8.2.1", "lib@!", "~Polly.Core@8.2.1"})
+	assert.Equal(t, as[0].(*component.Module).Line, 5)
+	assert.Assert(t, errors.Is(as[2].(*component.Module).Err, component.ErrSkip))
+	assert.DeepEqual(t, as[3].(*component.Module).Path, []string{"Polly"})
+}
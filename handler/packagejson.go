@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/izziiyt/compaa/component"
@@ -28,10 +32,26 @@ func (h *PackageJSON) LookUp(path string) (buf []component.Component, err error)
 		return
 	}
 
+	// engines.node is a range like ">=18", its lower bound is the oldest runtime to support
+	e := struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}{}
+	if json.Unmarshal(b, &e) == nil {
+		if v, ok := rangeFloor("nodejs", e.Engines.Node); ok {
+			lines := strings.Split(string(b), "\n")
+			buf = append(buf, &component.Language{Name: "nodejs", Version: v, Line: keyLine(lines, "engines", "node")})
+		}
+	}
+
 	ps, err := parsePackageJSON(b)
 	for _, p := range ps {
 		t := &component.Module{
-			Name: p.Name,
+			Ecosystem: component.EcosystemNPM,
+			Name:      p.Name,
+			Version:   p.Version,
+			Line:      p.Line,
 		}
 		buf = append(buf, t)
 	}
@@ -40,8 +60,10 @@ func (h *PackageJSON) LookUp(path string) (buf []component.Component, err error)
 }
 
 type pjJSON struct {
-	DEV  bool
-	Name string
+	DEV     bool
+	Name    string
+	Version string
+	Line    int
 }
 
 func parsePackageJSON(b []byte) (ps []*pjJSON, err error) {
@@ -52,21 +74,43 @@ func parsePackageJSON(b []byte) (ps []*pjJSON, err error) {
 	if err = json.Unmarshal(b, &j); err != nil {
 		return
 	}
-	for k := range j.Dependencies {
-		ps = append(ps, &pjJSON{DEV: false, Name: k})
+	lines := strings.Split(string(b), "\n")
+	for _, k := range slices.Sorted(maps.Keys(j.Dependencies)) {
+		ps = append(ps, &pjJSON{DEV: false, Name: k, Version: j.Dependencies[k], Line: keyLine(lines, "dependencies", k)})
 	}
-	for k := range j.DevDependencies {
-		ps = append(ps, &pjJSON{DEV: true, Name: k})
+	for _, k := range slices.Sorted(maps.Keys(j.DevDependencies)) {
+		ps = append(ps, &pjJSON{DEV: true, Name: k, Version: j.DevDependencies[k], Line: keyLine(lines, "devDependencies", k)})
 	}
 	return
 }
 
+// keyLine returns the 1-based line where key is declared inside the section object, or 0 if not found.
+func keyLine(lines []string, section, key string) int {
+	inSection := false
+	for i, l := range lines {
+		if !inSection {
+			inSection = strings.Contains(l, strconv.Quote(section))
+			continue
+		}
+		if strings.Contains(l, strconv.Quote(key)) {
+			return i + 1
+		}
+		if strings.Contains(l, "}") {
+			inSection = false
+		}
+	}
+	return 0
+}
+
 func (h *PackageJSON) SyncWithSource(c component.Component, ctx context.Context) component.Component {
 	switch v := c.(type) {
 	case *component.Module:
 		v = v.SyncWithNPM(ctx, h.HTTPClient)
 		v = v.SyncWithGitHub(ctx, h.GCli)
 		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, h.HTTPClient)
+		return v
 	default:
 		return v
 	}
@@ -11,12 +11,19 @@ func Test_PackageJSONLookUp(t *testing.T) {
 	h := &PackageJSON{}
 	as, err := h.LookUp("testdata/package.json")
 	assert.NilError(t, err)
-	assert.Equal(t, len(as), 3)
+	assert.Equal(t, len(as), 4)
 
-	m0 := as[0].(*component.Module)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "nodejs")
+	assert.Equal(t, l.Version, "18.17")
+	assert.Equal(t, l.Line, 15)
+
+	m0 := as[1].(*component.Module)
 	assert.Equal(t, m0.Name, "abc")
-	m1 := as[1].(*component.Module)
+	assert.Equal(t, m0.Line, 12)
+	m1 := as[2].(*component.Module)
 	assert.Equal(t, m1.Name, "aws-sdk")
-	m2 := as[2].(*component.Module)
+	m2 := as[3].(*component.Module)
 	assert.Equal(t, m2.Name, "minimist")
+	assert.Equal(t, m2.Line, 9)
 }
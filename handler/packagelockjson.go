@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// PackageLockJSON reads npm v2/v3 lockfiles (package-lock.json and npm-shrinkwrap.json).
+type PackageLockJSON struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+type packageLockEntry struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Link                 bool              `json:"link"`
+	Resolved             string            `json:"resolved"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+func (e *packageLockEntry) depNames() []string {
+	var names []string
+	for _, m := range []map[string]string{e.Dependencies, e.DevDependencies, e.OptionalDependencies, e.PeerDependencies} {
+		for k := range m {
+			names = append(names, k)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func (h *PackageLockJSON) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parsePackageLockJSON(b)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parsePackageLockJSON(b []byte) (*lockGraph, error) {
+	j := struct {
+		LockfileVersion int                          `json:"lockfileVersion"`
+		Packages        map[string]*packageLockEntry `json:"packages"`
+	}{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, err
+	}
+	if j.LockfileVersion < 2 || j.Packages == nil {
+		return nil, fmt.Errorf("unsupported lockfileVersion %v", j.LockfileVersion)
+	}
+
+	lines := strings.Split(string(b), "\n")
+	g := newLockGraph(component.EcosystemNPM)
+	var workspaces []string
+	for key, e := range j.Packages {
+		if e.Link {
+			continue
+		}
+		i := strings.LastIndex(key, "node_modules/")
+		if i < 0 {
+			// "" is the root project, other keys without node_modules are workspace members
+			workspaces = append(workspaces, key)
+			continue
+		}
+		name := e.Name
+		if name == "" {
+			name = key[i+len("node_modules/"):]
+		}
+		p := &lockPackage{name: name, version: e.Version, line: quotedLine(lines, key)}
+		for _, d := range e.depNames() {
+			if k, ok := resolveNodeModule(j.Packages, key, d); ok {
+				p.deps = append(p.deps, k)
+			}
+		}
+		g.pkgs[key] = p
+	}
+	slices.Sort(workspaces)
+	for _, w := range workspaces {
+		for _, d := range j.Packages[w].depNames() {
+			if k, ok := resolveNodeModule(j.Packages, w, d); ok {
+				g.roots = append(g.roots, k)
+			}
+		}
+	}
+	return g, nil
+}
+
+// resolveNodeModule finds the package which from requires as name, the same way node looks up node_modules.
+func resolveNodeModule(pkgs map[string]*packageLockEntry, from, name string) (string, bool) {
+	dir := from
+	for {
+		key := path.Join(dir, "node_modules", name)
+		if e, ok := pkgs[key]; ok {
+			if e.Link {
+				// workspace packages are linked and have no registry metadata
+				return "", false
+			}
+			return key, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		i := strings.LastIndex(dir, "/node_modules/")
+		if i < 0 {
+			dir = ""
+		} else {
+			dir = dir[:i]
+		}
+	}
+}
+
+// quotedLine returns the 1-based line where the quoted key first appears, or 0 if not found.
+func quotedLine(lines []string, key string) int {
+	q := strconv.Quote(key)
+	for i, l := range lines {
+		if strings.Contains(l, q) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (h *PackageLockJSON) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNPM(c, ctx, h.HTTPClient, h.GCli)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_PackageLockJSONLookUp(t *testing.T) {
+	h := &PackageLockJSON{}
+	as, err := h.LookUp("testdata/package-lock.json")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 3)
+
+	h = &PackageLockJSON{Transitive: true}
+	as, err = h.LookUp("testdata/package-lock.json")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 6)
+
+	m0 := as[0].(*component.Module)
+	assert.Equal(t, m0.Name, "abc")
+	assert.Equal(t, m0.Version, "0.6.1")
+	assert.Equal(t, m0.Line, 19)
+	assert.Assert(t, !m0.Indirect)
+	m3 := as[3].(*component.Module)
+	assert.Equal(t, m3.Name, "buffer")
+	assert.Assert(t, m3.Indirect)
+	assert.DeepEqual(t, m3.Path, []string{"aws-sdk"})
+	m5 := as[5].(*component.Module)
+	assert.Equal(t, m5.Name, "base64-js")
+	assert.DeepEqual(t, m5.Path, []string{"aws-sdk", "buffer"})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/izziiyt/compaa/component"
+)
+
+var (
+	pep508NameRegexp = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?`)
+	// a version clause like ">= 2.0" or "==1.*"
+	pep508ClauseRegexp = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*([A-Za-z0-9_.*+!-]+)$`)
+	// a url requirement ends at a ";" preceded by whitespace, since urls may contain ";"
+	pep508URLMarkerRegexp = regexp.MustCompile(`\s;`)
+)
+
+// pyRequirement is a dependency specification of PEP 508, like "requests[socks]>=2.31,<3; python_version >= '3.8'".
+type pyRequirement struct {
+	// Name is normalized per PEP 503
+	Name      string
+	Extras    []string
+	Specifier string
+	URL       string
+	Marker    string
+}
+
+func parsePEP508(s string) (*pyRequirement, error) {
+	rest := strings.TrimSpace(s)
+	name := pep508NameRegexp.FindString(rest)
+	if name == "" {
+		return nil, fmt.Errorf("invalid requirement %q: no name", s)
+	}
+	r := &pyRequirement{Name: normalizePyPIName(name)}
+	rest = strings.TrimSpace(rest[len(name):])
+
+	if strings.HasPrefix(rest, "[") {
+		i := strings.Index(rest, "]")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid requirement %q: unclosed extras", s)
+		}
+		for _, e := range strings.Split(rest[1:i], ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				r.Extras = append(r.Extras, normalizePyPIName(e))
+			}
+		}
+		rest = strings.TrimSpace(rest[i+1:])
+	}
+
+	if strings.HasPrefix(rest, "@") {
+		rest = strings.TrimSpace(rest[1:])
+		if loc := pep508URLMarkerRegexp.FindStringIndex(rest); loc != nil {
+			r.Marker = strings.TrimSpace(rest[loc[1]:])
+			rest = rest[:loc[0]]
+		}
+		r.URL = strings.TrimSpace(rest)
+		if r.URL == "" {
+			return nil, fmt.Errorf("invalid requirement %q: empty url", s)
+		}
+		return r, nil
+	}
+
+	spec, marker, _ := strings.Cut(rest, ";")
+	r.Marker = strings.TrimSpace(marker)
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		spec = strings.TrimSpace(spec[1 : len(spec)-1])
+	}
+	if spec == "" {
+		return r, nil
+	}
+	var clauses []string
+	for _, c := range strings.Split(spec, ",") {
+		m := pep508ClauseRegexp.FindStringSubmatch(strings.TrimSpace(c))
+		if m == nil {
+			return nil, fmt.Errorf("invalid requirement %q: bad version specifier %q", s, strings.TrimSpace(c))
+		}
+		clauses = append(clauses, m[1]+m[2])
+	}
+	r.Specifier = strings.Join(clauses, ",")
+	return r, nil
+}
+
+// version returns the version the requirement pins with "==" or "===", or the specifier itself.
+func (r *pyRequirement) version() string {
+	return specifierVersion(r.Specifier)
+}
+
+// module converts a requirement at line into a module. Requirements on urls aren't on PyPI and are skipped.
+func (r *pyRequirement) module(line int) *component.Module {
+	m := &component.Module{Ecosystem: component.EcosystemPyPI, Name: r.Name, Version: r.version(), Line: line}
+	if r.URL != "" {
+		m.Err = fmt.Errorf("%w: installed from %v", component.ErrSkip, r.URL)
+	}
+	return m
+}
+
+// requirementModule parses s and converts it into a module. An invalid requirement becomes a module with the error.
+func requirementModule(s string, line int) *component.Module {
+	r, err := parsePEP508(s)
+	if err != nil {
+		return &component.Module{Ecosystem: component.EcosystemPyPI, Name: strings.TrimSpace(s), Line: line, Err: err}
+	}
+	return r.module(line)
+}
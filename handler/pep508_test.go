@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ParsePEP508(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		r   *pyRequirement
+		err bool
+	}{
+		{s: "requests", r: &pyRequirement{Name: "requests"}},
+		{s: "Django~=4.2", r: &pyRequirement{Name: "django", Specifier: "~=4.2"}},
+		{s: "pkg[Extra_One, two]==1.0", r: &pyRequirement{Name: "pkg", Extras: []string{"extra-one", "two"}, Specifier: "==1.0"}},
+		{s: "Flask >= 2.0, < 3.0", r: &pyRequirement{Name: "flask", Specifier: ">=2.0,<3.0"}},
+		{s: "name (>=1.0)", r: &pyRequirement{Name: "name", Specifier: ">=1.0"}},
+		{s: `name ; python_version<"3.8"`, r: &pyRequirement{Name: "name", Marker: `python_version<"3.8"`}},
+		{s: "zope.interface>=5; sys_platform == 'win32'", r: &pyRequirement{Name: "zope-interface", Specifier: ">=5", Marker: "sys_platform == 'win32'"}},
+		{s: "pip @ https://example.com/pip.zip;sha1=abc ; python_version >= '3'", r: &pyRequirement{Name: "pip", URL: "https://example.com/pip.zip;sha1=abc", Marker: "python_version >= '3'"}},
+		{s: "name==", err: true},
+		{s: "name[extra", err: true},
+		{s: "-e .", err: true},
+	} {
+		r, err := parsePEP508(tt.s)
+		if tt.err {
+			assert.Assert(t, err != nil, tt.s)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r, tt.r)
+	}
+}
+
+func Test_SpecifierVersion(t *testing.T) {
+	assert.Equal(t, specifierVersion("==2.31.0"), "2.31.0")
+	assert.Equal(t, specifierVersion("===4.12.2"), "4.12.2")
+	assert.Equal(t, specifierVersion("2.31.0"), "2.31.0")
+	assert.Equal(t, specifierVersion("==2.*"), "==2.*")
+	assert.Equal(t, specifierVersion(">=2.0,<3"), ">=2.0,<3")
+	assert.Equal(t, specifierVersion("*"), "")
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// Pipfile reads the packages and dev-packages of Pipfile. The required python is checked as a runtime.
+type Pipfile struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+type pipfile struct {
+	Packages    map[string]any `toml:"packages"`
+	DevPackages map[string]any `toml:"dev-packages"`
+	Requires    struct {
+		PythonVersion     string `toml:"python_version"`
+		PythonFullVersion string `toml:"python_full_version"`
+	} `toml:"requires"`
+}
+
+func parsePipfile(b []byte) (*pipfile, error) {
+	pf := &pipfile{}
+	if _, err := toml.Decode(string(b), pf); err != nil {
+		return nil, err
+	}
+	return pf, nil
+}
+
+func (h *Pipfile) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	pf, err := parsePipfile(b)
+	if err != nil {
+		return
+	}
+
+	if v := pf.Requires.PythonFullVersion; v != "" {
+		buf = append(buf, &component.Language{Name: "python", Version: v, Line: tomlKeyLine(b, "requires", "python_full_version")})
+	} else if v := pf.Requires.PythonVersion; v != "" {
+		buf = append(buf, &component.Language{Name: "python", Version: v, Line: tomlKeyLine(b, "requires", "python_version")})
+	}
+
+	seen := map[string]bool{}
+	for _, t := range []struct {
+		table string
+		deps  map[string]any
+	}{{"packages", pf.Packages}, {"dev-packages", pf.DevPackages}} {
+		table, deps := t.table, t.deps
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			key := normalizePyPIName(name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			m := &component.Module{Ecosystem: component.EcosystemPyPI, Name: key, Line: tomlKeyLine(b, table, name)}
+			m.Version, m.Err = pyDependencyVersion(deps[name])
+			buf = append(buf, m)
+		}
+	}
+	return
+}
+
+func (h *Pipfile) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
+}
+
+// PipfileLock reads Pipfile.lock. Packages missing from the sibling Pipfile are indirect,
+// since the lockfile doesn't record which package requires which.
+type PipfileLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+type pipfileLockEntry struct {
+	Version string `json:"version"`
+	Git     string `json:"git"`
+	Path    string `json:"path"`
+	File    string `json:"file"`
+}
+
+func (h *PipfileLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lock := struct {
+		Default map[string]pipfileLockEntry `json:"default"`
+		Develop map[string]pipfileLockEntry `json:"develop"`
+	}{}
+	if err = json.Unmarshal(b, &lock); err != nil {
+		return
+	}
+
+	var direct map[string]bool
+	if pb, err := os.ReadFile(filepath.Join(filepath.Dir(path), "Pipfile")); err == nil {
+		if pf, err := parsePipfile(pb); err == nil {
+			direct = map[string]bool{}
+			for _, name := range slices.Concat(slices.Collect(maps.Keys(pf.Packages)), slices.Collect(maps.Keys(pf.DevPackages))) {
+				direct[normalizePyPIName(name)] = true
+			}
+		}
+	}
+
+	lines := strings.Split(string(b), "\n")
+	seen := map[string]bool{}
+	for _, entries := range []map[string]pipfileLockEntry{lock.Default, lock.Develop} {
+		for _, name := range slices.Sorted(maps.Keys(entries)) {
+			key := normalizePyPIName(name)
+			indirect := direct != nil && !direct[key]
+			if seen[key] || (indirect && !h.Transitive) {
+				continue
+			}
+			seen[key] = true
+			e := entries[name]
+			m := &component.Module{Ecosystem: component.EcosystemPyPI, Name: key, Line: quotedLine(lines, name), Indirect: indirect}
+			m.Version, m.Err = pyDependencyVersion(map[string]any{"version": e.Version, "git": e.Git, "path": e.Path, "file": e.File})
+			buf = append(buf, m)
+		}
+	}
+	return
+}
+
+func (h *PipfileLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
+}
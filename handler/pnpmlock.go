@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+	"gopkg.in/yaml.v3"
+)
+
+// PNPMLock reads pnpm lockfiles of lockfileVersion 6 and 9.
+type PNPMLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+// pnpmDep is a dependency of an importer, either "1.0.0" or {specifier: ^1.0.0, version: 1.0.0}.
+type pnpmDep struct {
+	Version string
+}
+
+func (d *pnpmDep) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		d.Version = n.Value
+		return nil
+	}
+	v := struct {
+		Version string `yaml:"version"`
+	}{}
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	d.Version = v.Version
+	return nil
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmDep `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmDep `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmDep `yaml:"optionalDependencies"`
+}
+
+func (i *pnpmImporter) deps() map[string]string {
+	deps := map[string]string{}
+	for _, m := range []map[string]pnpmDep{i.Dependencies, i.DevDependencies, i.OptionalDependencies} {
+		for k, v := range m {
+			deps[k] = v.Version
+		}
+	}
+	return deps
+}
+
+type pnpmSnapshot struct {
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+func (h *PNPMLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parsePNPMLock(b)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parsePNPMLock(b []byte) (*lockGraph, error) {
+	lock := struct {
+		Importers map[string]*pnpmImporter `yaml:"importers"`
+		Packages  map[string]*pnpmSnapshot `yaml:"packages"`
+		Snapshots map[string]*pnpmSnapshot `yaml:"snapshots"`
+	}{}
+	if err := yaml.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+	lines, err := pnpmKeyLines(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// v9 moved dependencies of packages to snapshots, v6 keeps them in packages with a leading "/"
+	snapshots := lock.Snapshots
+	if snapshots == nil {
+		snapshots = lock.Packages
+	}
+	keys := map[string]string{}
+	for k := range snapshots {
+		keys[strings.TrimPrefix(k, "/")] = k
+	}
+	resolve := func(name, version string) (string, bool) {
+		k, ok := keys[name+"@"+version]
+		return k, ok
+	}
+
+	g := newLockGraph(component.EcosystemNPM)
+	for k, s := range snapshots {
+		name, version := splitDescriptor(strings.TrimPrefix(k, "/"))
+		// drop the peer dependency suffix of "1.0.0(react@18.2.0)"
+		version, _, _ = strings.Cut(version, "(")
+		p := &lockPackage{name: name, version: version, line: lines[name+"@"+version]}
+		deps := map[string]string{}
+		maps.Copy(deps, s.Dependencies)
+		maps.Copy(deps, s.OptionalDependencies)
+		for _, d := range slices.Sorted(maps.Keys(deps)) {
+			if dk, ok := resolve(d, deps[d]); ok {
+				p.deps = append(p.deps, dk)
+			}
+		}
+		g.pkgs[k] = p
+	}
+
+	importers := lock.Importers
+	if importers == nil {
+		// a project without workspaces keeps its dependencies at the top level
+		root := &pnpmImporter{}
+		if err := yaml.Unmarshal(b, root); err != nil {
+			return nil, err
+		}
+		importers = map[string]*pnpmImporter{".": root}
+	}
+	for _, i := range slices.Sorted(maps.Keys(importers)) {
+		deps := importers[i].deps()
+		for _, d := range slices.Sorted(maps.Keys(deps)) {
+			if k, ok := resolve(d, deps[d]); ok {
+				g.roots = append(g.roots, k)
+			}
+		}
+	}
+	return g, nil
+}
+
+// pnpmKeyLines maps "name@version" of packages and snapshots to the lines they are first declared at.
+func pnpmKeyLines(b []byte) (map[string]int, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	lines := map[string]int{}
+	if len(doc.Content) == 0 {
+		return lines, nil
+	}
+	m := doc.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != "packages" && m.Content[i].Value != "snapshots" {
+			continue
+		}
+		pm := m.Content[i+1]
+		for j := 0; j+1 < len(pm.Content); j += 2 {
+			k, _, _ := strings.Cut(strings.TrimPrefix(pm.Content[j].Value, "/"), "(")
+			if _, ok := lines[k]; !ok {
+				lines[k] = pm.Content[j].Line
+			}
+		}
+	}
+	return lines, nil
+}
+
+func (h *PNPMLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNPM(c, ctx, h.HTTPClient, h.GCli)
+}
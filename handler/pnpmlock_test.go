@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_PNPMLockLookUp(t *testing.T) {
+	h := &PNPMLock{Transitive: true}
+	as, err := h.LookUp("testdata/pnpm-lock.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 5)
+
+	m1 := as[1].(*component.Module)
+	assert.Equal(t, m1.Name, "react-dom")
+	assert.Equal(t, m1.Version, "18.2.0")
+	assert.Equal(t, m1.Line, 28)
+	assert.Assert(t, !m1.Indirect)
+	m4 := as[4].(*component.Module)
+	assert.Equal(t, m4.Name, "js-tokens")
+	assert.Assert(t, m4.Indirect)
+	assert.DeepEqual(t, m4.Path, []string{"react", "loose-envify"})
+
+	as, err = h.LookUp("testdata/pnpm6-lock.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 3)
+	m0 := as[0].(*component.Module)
+	assert.Equal(t, m0.Name, "react")
+	assert.Equal(t, m0.Line, 21)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// PoetryLock reads poetry.lock. The direct dependencies are taken from the sibling pyproject.toml.
+type PoetryLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+func (h *PoetryLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parsePoetryLock(b, directPyPINames(filepath.Join(filepath.Dir(path), "pyproject.toml")))
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parsePoetryLock(b []byte, direct map[string]bool) (*lockGraph, error) {
+	lock := struct {
+		Package []struct {
+			Name         string         `toml:"name"`
+			Version      string         `toml:"version"`
+			Dependencies map[string]any `toml:"dependencies"`
+			Source       struct {
+				Type string `toml:"type"`
+				URL  string `toml:"url"`
+			} `toml:"source"`
+		} `toml:"package"`
+	}{}
+	if _, err := toml.Decode(string(b), &lock); err != nil {
+		return nil, err
+	}
+	lines := tomlArrayLines(b, "package")
+
+	// poetry locks a single version of each package
+	g := newLockGraph(component.EcosystemPyPI)
+	for i, p := range lock.Package {
+		lp := &lockPackage{name: p.Name, version: p.Version}
+		if i < len(lines) {
+			lp.line = lines[i]
+		}
+		// "legacy" sources are private indexes, the others aren't indexes at all
+		switch p.Source.Type {
+		case "git", "directory", "file", "url":
+			lp.err = fmt.Errorf("%w: installed from %v %v", component.ErrSkip, p.Source.Type, p.Source.URL)
+		}
+		for _, d := range slices.Sorted(maps.Keys(p.Dependencies)) {
+			lp.deps = append(lp.deps, normalizePyPIName(d))
+		}
+		g.pkgs[normalizePyPIName(p.Name)] = lp
+	}
+	for _, k := range slices.Sorted(maps.Keys(direct)) {
+		if _, ok := g.pkgs[k]; ok {
+			g.roots = append(g.roots, k)
+		}
+	}
+	if len(g.roots) == 0 {
+		// without pyproject.toml, packages nothing depends on are taken as direct dependencies
+		g.orphanRoots()
+	}
+	return g, nil
+}
+
+func (h *PoetryLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
+}
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// PomXML reads the parent, dependencies and dependencyManagement of pom.xml. Properties and managed versions are taken
+// from local parent POMs too. The Java release the project compiles for is checked as a runtime.
+type PomXML struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+type pomDependency struct {
+	GroupID      string  `xml:"groupId"`
+	ArtifactID   string  `xml:"artifactId"`
+	Version      string  `xml:"version"`
+	RelativePath *string `xml:"relativePath"`
+	line         int
+}
+
+type pom struct {
+	Parent       *pomDependency
+	GroupID      string
+	ArtifactID   string
+	Version      string
+	Properties   map[string]string
+	Dependencies []*pomDependency
+	Managed      []*pomDependency
+	// propertyLines maps properties to the lines they are declared at
+	propertyLines map[string]int
+}
+
+// javaReleaseProperties are the properties telling the Java release, in order of precedence.
+var javaReleaseProperties = []string{"maven.compiler.release", "maven.compiler.source", "maven.compiler.target", "java.version"}
+
+var pomPropertyRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func parsePom(b []byte) (*pom, error) {
+	p := &pom{Properties: map[string]string{}, propertyLines: map[string]int{}}
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var stack []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch se := tok.(type) {
+		case xml.StartElement:
+			path := strings.Join(stack, "/")
+			line, _ := d.InputPos()
+			switch {
+			case path == "project" && se.Name.Local == "parent":
+				p.Parent = &pomDependency{line: line}
+				if err := d.DecodeElement(p.Parent, &se); err != nil {
+					return nil, err
+				}
+			case path == "project/dependencies" && se.Name.Local == "dependency":
+				dep := &pomDependency{line: line}
+				if err := d.DecodeElement(dep, &se); err != nil {
+					return nil, err
+				}
+				p.Dependencies = append(p.Dependencies, dep)
+			case path == "project/dependencyManagement/dependencies" && se.Name.Local == "dependency":
+				dep := &pomDependency{line: line}
+				if err := d.DecodeElement(dep, &se); err != nil {
+					return nil, err
+				}
+				p.Managed = append(p.Managed, dep)
+			case path == "project/properties":
+				var v string
+				if err := d.DecodeElement(&v, &se); err != nil {
+					return nil, err
+				}
+				p.Properties[se.Name.Local] = strings.TrimSpace(v)
+				p.propertyLines[se.Name.Local] = line
+			case path == "project" && (se.Name.Local == "groupId" || se.Name.Local == "artifactId" || se.Name.Local == "version"):
+				var v string
+				if err := d.DecodeElement(&v, &se); err != nil {
+					return nil, err
+				}
+				switch se.Name.Local {
+				case "groupId":
+					p.GroupID = strings.TrimSpace(v)
+				case "artifactId":
+					p.ArtifactID = strings.TrimSpace(v)
+				case "version":
+					p.Version = strings.TrimSpace(v)
+				}
+			default:
+				stack = append(stack, se.Name.Local)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if p.Parent != nil {
+		// the coordinates default to the ones of the parent
+		if p.GroupID == "" {
+			p.GroupID = p.Parent.GroupID
+		}
+		if p.Version == "" {
+			p.Version = p.Parent.Version
+		}
+	}
+	return p, nil
+}
+
+// inherit merges properties and managed versions of local parent POMs into p, the ones of p taking precedence.
+func (p *pom) inherit(path string, depth int) {
+	if p.Parent == nil || depth > 5 {
+		return
+	}
+	rel := "../pom.xml"
+	if p.Parent.RelativePath != nil {
+		// an empty <relativePath/> means the parent is only in repositories
+		rel = strings.TrimSpace(*p.Parent.RelativePath)
+	}
+	if rel == "" {
+		return
+	}
+	parentPath := filepath.Join(filepath.Dir(path), rel)
+	if !strings.HasSuffix(parentPath, ".xml") {
+		parentPath = filepath.Join(parentPath, "pom.xml")
+	}
+	b, err := os.ReadFile(parentPath)
+	if err != nil {
+		return
+	}
+	parent, err := parsePom(b)
+	if err != nil || parent.ArtifactID != p.Parent.ArtifactID {
+		return
+	}
+	parent.inherit(parentPath, depth+1)
+	for k, v := range parent.Properties {
+		if _, ok := p.Properties[k]; !ok {
+			p.Properties[k] = v
+		}
+	}
+	for _, m := range parent.Managed {
+		// managed versions of the parent are resolved with its own properties
+		inherited := *m
+		inherited.GroupID, inherited.Version = parent.interpolate(m.GroupID), parent.interpolate(m.Version)
+		inherited.line = 0
+		p.Managed = append(p.Managed, &inherited)
+	}
+}
+
+// interpolate replaces ${...} with properties and coordinates of the project. Unknown references are left as they are.
+func (p *pom) interpolate(s string) string {
+	for i := 0; i < 10 && strings.Contains(s, "${"); i++ {
+		replaced := pomPropertyRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			switch name {
+			case "project.version", "pom.version", "version":
+				return p.Version
+			case "project.groupId", "pom.groupId":
+				return p.GroupID
+			case "project.artifactId", "pom.artifactId":
+				return p.ArtifactID
+			case "project.parent.version":
+				if p.Parent != nil {
+					return p.Parent.Version
+				}
+			}
+			if v, ok := p.Properties[name]; ok {
+				return v
+			}
+			return ref
+		})
+		if replaced == s {
+			break
+		}
+		s = replaced
+	}
+	return s
+}
+
+func (h *PomXML) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	p, err := parsePom(b)
+	if err != nil {
+		return
+	}
+	p.inherit(path, 0)
+
+	for _, k := range javaReleaseProperties {
+		if v, ok := p.Properties[k]; ok {
+			if v = javaRelease(p.interpolate(v)); v != "" {
+				buf = append(buf, &component.Language{Name: "java", Version: v, Line: p.propertyLines[k]})
+				break
+			}
+		}
+	}
+
+	managed := map[string]string{}
+	for _, m := range p.Managed {
+		k := p.interpolate(m.GroupID) + ":" + p.interpolate(m.ArtifactID)
+		if _, ok := managed[k]; !ok {
+			managed[k] = p.interpolate(m.Version)
+		}
+	}
+
+	seen := map[string]bool{}
+	var deps []*pomDependency
+	if p.Parent != nil {
+		deps = append(deps, p.Parent)
+	}
+	deps = append(deps, p.Dependencies...)
+	for _, m := range p.Managed {
+		// inherited entries are reported by the parent POM
+		if m.line > 0 {
+			deps = append(deps, m)
+		}
+	}
+	for _, d := range deps {
+		m := &component.Module{
+			Ecosystem: component.EcosystemMaven,
+			Name:      p.interpolate(d.GroupID) + ":" + p.interpolate(d.ArtifactID),
+			Version:   p.interpolate(d.Version),
+			Line:      d.line,
+		}
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+		if m.Version == "" {
+			m.Version = managed[m.Name]
+		}
+		buf = append(buf, m)
+	}
+	return
+}
+
+// javaRelease normalizes a Java release like "17" or "1.8" into its major version.
+func javaRelease(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "1.")
+	major, _, _ := strings.Cut(v, ".")
+	for _, r := range major {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return major
+}
+
+func (h *PomXML) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithMaven(c, ctx, h.HTTPClient, h.GCli)
+}
+
+func syncWithMaven(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		v = v.SyncWithMaven(ctx, cli)
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, cli)
+		return v
+	default:
+		return v
+	}
+}
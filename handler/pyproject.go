@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// PyProject reads pyproject.toml: PEP 621 dependencies, optional-dependencies and dependency groups,
+// and the dependencies of Poetry. The required python is checked as a runtime.
+type PyProject struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+}
+
+type poetryDependencies map[string]any
+
+type pyProject struct {
+	Project struct {
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	// PEP 735 groups, entries are requirements or {include-group = "..."}
+	DependencyGroups map[string][]any `toml:"dependency-groups"`
+	Tool             struct {
+		Poetry struct {
+			Dependencies    poetryDependencies `toml:"dependencies"`
+			DevDependencies poetryDependencies `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies poetryDependencies `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+func parsePyProject(b []byte) (*pyProject, error) {
+	pp := &pyProject{}
+	if _, err := toml.Decode(string(b), pp); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+func (h *PyProject) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	pp, err := parsePyProject(b)
+	if err != nil {
+		return
+	}
+
+	// PEP 621 requires-python, or python among the poetry dependencies
+	constraint, pattern := pp.Project.RequiresPython, requiresPythonRegexp
+	if constraint == "" {
+		constraint, _ = pp.Tool.Poetry.Dependencies["python"].(string)
+		pattern = poetryPythonRegexp
+	}
+	if v, ok := rangeFloor("python", constraint); ok {
+		buf = append(buf, &component.Language{Name: "python", Version: v, Line: matchLine(b, pattern)})
+	}
+	buf = append(buf, pp.modules(b)...)
+	return
+}
+
+var (
+	requiresPythonRegexp = regexp.MustCompile(`^\s*requires-python\s*=`)
+	poetryPythonRegexp   = regexp.MustCompile(`^\s*python\s*=`)
+)
+
+// modules returns the dependencies declared by pyproject.toml. A name is reported once.
+func (pp *pyProject) modules(b []byte) (buf []component.Component) {
+	lines := strings.Split(string(b), "\n")
+	seen := map[string]bool{}
+	add := func(m *component.Module) {
+		if !seen[m.Name] {
+			seen[m.Name] = true
+			buf = append(buf, m)
+		}
+	}
+
+	requirements := slices.Clone(pp.Project.Dependencies)
+	for _, k := range slices.Sorted(maps.Keys(pp.Project.OptionalDependencies)) {
+		requirements = append(requirements, pp.Project.OptionalDependencies[k]...)
+	}
+	for _, k := range slices.Sorted(maps.Keys(pp.DependencyGroups)) {
+		for _, r := range pp.DependencyGroups[k] {
+			if s, ok := r.(string); ok {
+				requirements = append(requirements, s)
+			}
+		}
+	}
+	for _, r := range requirements {
+		add(requirementModule(r, quotedLine(lines, r)))
+	}
+
+	poetry := pp.Tool.Poetry
+	tables := map[string]poetryDependencies{
+		"tool.poetry.dependencies":     poetry.Dependencies,
+		"tool.poetry.dev-dependencies": poetry.DevDependencies,
+	}
+	for g, v := range poetry.Group {
+		tables["tool.poetry.group."+g+".dependencies"] = v.Dependencies
+	}
+	for _, table := range slices.Sorted(maps.Keys(tables)) {
+		deps := tables[table]
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			if name == "python" {
+				continue
+			}
+			m := &component.Module{Ecosystem: component.EcosystemPyPI, Name: normalizePyPIName(name), Line: tomlKeyLine(b, table, name)}
+			m.Version, m.Err = pyDependencyVersion(deps[name])
+			add(m)
+		}
+	}
+	return
+}
+
+// pyDependencyVersion returns the version pinned by a dependency of Poetry or Pipfile, "2.31.0", "^2.31" or {version = "^2.31", extras = [...]}.
+// Dependencies on paths, files, git repositories or urls aren't on PyPI and are skipped.
+func pyDependencyVersion(v any) (string, error) {
+	switch d := v.(type) {
+	case string:
+		return specifierVersion(d), nil
+	case map[string]any:
+		for _, k := range []string{"path", "file", "git", "url"} {
+			if s, ok := d[k].(string); ok && s != "" {
+				return "", fmt.Errorf("%w: installed from %v %v", component.ErrSkip, k, s)
+			}
+		}
+		s, _ := d["version"].(string)
+		return specifierVersion(s), nil
+	case []any:
+		// multiple constraints for different pythons
+		if len(d) > 0 {
+			return pyDependencyVersion(d[0])
+		}
+	}
+	return "", nil
+}
+
+// directPyPINames returns the normalized names of the dependencies declared by the pyproject.toml at path.
+func directPyPINames(path string) map[string]bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	pp, err := parsePyProject(b)
+	if err != nil {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, c := range pp.modules(b) {
+		names[c.(*component.Module).Name] = true
+	}
+	return names
+}
+
+func (h *PyProject) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+// describeModules formats modules as "name@version", with a leading "~" for indirect ones and a trailing "!" for skipped ones.
+func describeModules(as []component.Component) (s []string) {
+	for _, a := range as {
+		m, ok := a.(*component.Module)
+		if !ok {
+			continue
+		}
+		d := m.Name + "@" + m.Version
+		if m.Indirect {
+			d = "~" + d
+		}
+		if errors.Is(m.Err, component.ErrSkip) {
+			d += "!"
+		}
+		s = append(s, d)
+	}
+	return
+}
+
+func Test_PyProjectLookUp(t *testing.T) {
+	h := &PyProject{}
+	as, err := h.LookUp("testdata/pyproject.toml")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Name, "python")
+	assert.Equal(t, l.Version, "3.9")
+	assert.Equal(t, l.Line, 4)
+	assert.DeepEqual(t, describeModules(as), []string{"requests@>=2.31", "django@4.2.7", "attrs@", "pytest@>=8", "ruff@0.4.4"})
+	assert.Equal(t, as[2].(*component.Module).Line, 7)
+	assert.Equal(t, as[5].(*component.Module).Line, 19)
+
+	as, err = h.LookUp("testdata/poetry/pyproject.toml")
+	assert.NilError(t, err)
+	l = as[0].(*component.Language)
+	assert.Equal(t, l.Version, "3.10")
+	assert.Equal(t, l.Line, 6)
+	assert.DeepEqual(t, describeModules(as), []string{"mylib@!", "requests@^2.31", "typing-extensions@4.12.2", "pytest@^8.0"})
+	assert.Equal(t, as[3].(*component.Module).Line, 8)
+	assert.Equal(t, as[4].(*component.Module).Line, 12)
+}
+
+func Test_PoetryLockLookUp(t *testing.T) {
+	h := &PoetryLock{}
+	as, err := h.LookUp("testdata/poetry/poetry.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{"mylib@0.1.0!", "pytest@8.2.0", "requests@2.31.0", "typing-extensions@4.12.2"})
+	assert.Equal(t, as[2].(*component.Module).Line, 39)
+
+	h.Transitive = true
+	as, err = h.LookUp("testdata/poetry/poetry.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"mylib@0.1.0!", "pytest@8.2.0", "requests@2.31.0", "typing-extensions@4.12.2",
+		"~iniconfig@2.0.0", "~certifi@2024.2.2", "~urllib3@2.2.1",
+	})
+	assert.DeepEqual(t, as[6].(*component.Module).Path, []string{"requests"})
+}
+
+func Test_UVLockLookUp(t *testing.T) {
+	h := &UVLock{Transitive: true}
+	as, err := h.LookUp("testdata/uv/uv.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"requests@2.31.0", "tools@0.2.0!", "pytest@8.2.0",
+		"~certifi@2024.2.2", "~urllib3@2.2.1", "~iniconfig@2.0.0",
+	})
+	assert.Equal(t, as[0].(*component.Module).Line, 22)
+}
+
+func Test_PipfileLookUp(t *testing.T) {
+	h := &Pipfile{}
+	as, err := h.LookUp("testdata/pipenv/Pipfile")
+	assert.NilError(t, err)
+	l := as[0].(*component.Language)
+	assert.Equal(t, l.Version, "3.11")
+	assert.Equal(t, l.Line, 15)
+	assert.DeepEqual(t, describeModules(as), []string{"django@4.2.7", "mylib@!", "requests@", "pytest@>=8.0"})
+	assert.Equal(t, as[1].(*component.Module).Line, 8)
+}
+
+func Test_PipfileLockLookUp(t *testing.T) {
+	h := &PipfileLock{}
+	as, err := h.LookUp("testdata/pipenv/Pipfile.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{"django@4.2.7", "mylib@!", "requests@2.31.0", "pytest@8.2.0"})
+	assert.Equal(t, as[0].(*component.Module).Line, 16)
+
+	h.Transitive = true
+	as, err = h.LookUp("testdata/pipenv/Pipfile.lock")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{"~certifi@2024.2.2", "django@4.2.7", "mylib@!", "requests@2.31.0", "pytest@8.2.0"})
+}
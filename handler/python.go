@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+var (
+	// runs of the separators PEP 503 folds into a single "-"
+	pep503Regexp = regexp.MustCompile(`[-_.]+`)
+	// an exact version without any operator
+	exactVersionRegexp = regexp.MustCompile(`^\d[\w.!+-]*$`)
+)
+
+// normalizePyPIName normalizes a distribution name per PEP 503, e.g. "Foo_Bar" to "foo-bar".
+func normalizePyPIName(name string) string {
+	return pep503Regexp.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// specifierVersion returns the version a specifier like "==2.31.0" or poetry's "2.31.0" pins.
+// Ranges like ">=2.31" or "^2.31" are returned as they are, and "*" as "".
+func specifierVersion(spec string) string {
+	spec = strings.TrimSpace(spec)
+	if spec == "*" {
+		return ""
+	}
+	if v := strings.TrimPrefix(strings.TrimPrefix(spec, "==="), "=="); exactVersionRegexp.MatchString(v) {
+		return v
+	}
+	return spec
+}
+
+// tomlKeyLine returns the 1-based line where key is assigned, quoted or not, after the table header, or 0 if not found.
+func tomlKeyLine(b []byte, table, key string) int {
+	pattern := regexp.MustCompile(`^\s*["']?` + regexp.QuoteMeta(key) + `["']?\s*=`)
+	inTable := table == ""
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inTable = table == "" || line == "["+table+"]"
+			continue
+		}
+		if inTable && pattern.MatchString(line) {
+			return n
+		}
+	}
+	return 0
+}
+
+// matchLine returns the 1-based line of the first match of pattern, or 0 if none matches.
+func matchLine(b []byte, pattern *regexp.Regexp) int {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		if pattern.MatchString(scanner.Text()) {
+			return n
+		}
+	}
+	return 0
+}
+
+// tomlArrayLines returns the 1-based lines of the headers of an array of tables like [[package]], in order.
+func tomlArrayLines(b []byte, table string) (lines []int) {
+	header := "[[" + table + "]]"
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == header {
+			lines = append(lines, n)
+		}
+	}
+	return
+}
+
+func syncWithPyPI(c component.Component, ctx context.Context, cli *http.Client, gcli *github.Client) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		v = v.SyncWithPypi(ctx, cli)
+		v = v.SyncWithGitHub(ctx, gcli)
+		return v
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, cli)
+		return v
+	default:
+		return v
+	}
+}
@@ -3,58 +3,178 @@ package handler
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/izziiyt/compaa/component"
 )
 
+// RequirementsTXT reads pip requirements files. Requirements of files included by -r are reported at the line of the -r,
+// and versions pinned by constraints files included by -c replace the ranges of requirements.
 type RequirementsTXT struct {
 	GCli       *github.Client
 	HTTPClient *http.Client
 }
 
+var (
+	// "#" starts a comment at the beginning of a line or after whitespace
+	requirementsCommentRegexp = regexp.MustCompile(`(^|\s)#.*$`)
+	// per-requirement options like --hash=sha256:... follow the requirement
+	requirementsOptionRegexp = regexp.MustCompile(`\s--?[A-Za-z]`)
+	// "#egg=name" of editable and vcs urls
+	eggRegexp = regexp.MustCompile(`[#&]egg=([A-Za-z0-9._-]+)`)
+	// "name @ url" names the project of a url
+	urlRequirementRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*\s*(?:\[[^\]]*\])?\s*@`)
+)
+
 func (h *RequirementsTXT) LookUp(path string) (buf []component.Component, err error) {
+	ms, constraints, err := readRequirementsTXT(path, map[string]bool{})
+	if err != nil {
+		return
+	}
+	for _, m := range ms {
+		if v, ok := constraints[m.Name]; ok && m.Err == nil && !exactVersionRegexp.MatchString(m.Version) {
+			m.Version = v
+		}
+		buf = append(buf, m)
+	}
+	return
+}
+
+// readRequirementsTXT reads the requirements of path and the files it includes, and the versions pinned by constraints files.
+// seen breaks include cycles. A name is reported once.
+func readRequirementsTXT(path string, seen map[string]bool) (ms []*component.Module, constraints map[string]string, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	constraints = map[string]string{}
+	if seen[abs] {
+		return
+	}
+	seen[abs] = true
+
 	f, err := os.Open(path)
 	if err != nil {
 		return
 	}
 	defer f.Close()
 
+	names := map[string]bool{}
+	add := func(m *component.Module) {
+		if !names[m.Name] {
+			names[m.Name] = true
+			ms = append(ms, m)
+		}
+	}
+	include := func(name string) (sub []*component.Module, c map[string]string, err error) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		return readRequirementsTXT(name, seen)
+	}
+
 	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "-r") {
+	var logical string
+	start := 0
+	for n := 1; scanner.Scan(); n++ {
+		text := requirementsCommentRegexp.ReplaceAllString(scanner.Text(), "")
+		if logical == "" {
+			start = n
+		}
+		// a trailing backslash continues the line
+		if strings.HasSuffix(text, `\`) {
+			logical += strings.TrimSuffix(text, `\`) + " "
 			continue
 		}
-		if strings.HasPrefix(line, "-c") {
+		line := strings.TrimSpace(logical + text)
+		logical = ""
+		if line == "" {
 			continue
 		}
-		if strings.HasPrefix(line, "./") {
+
+		if strings.HasPrefix(line, "-") {
+			if v, ok := requirementsOption(line, "-r", "--requirement"); ok {
+				sub, c, err := include(v)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, m := range sub {
+					m.Line = start
+					add(m)
+				}
+				for k, v := range c {
+					constraints[k] = v
+				}
+			} else if v, ok := requirementsOption(line, "-c", "--constraint"); ok {
+				sub, c, err := include(v)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, m := range sub {
+					if m.Err == nil && m.Version != "" {
+						constraints[m.Name] = m.Version
+					}
+				}
+				for k, v := range c {
+					constraints[k] = v
+				}
+			} else if v, ok := requirementsOption(line, "-e", "--editable"); ok {
+				if egg := eggRegexp.FindStringSubmatch(v); egg != nil {
+					add(&component.Module{
+						Ecosystem: component.EcosystemPyPI,
+						Name:      normalizePyPIName(egg[1]),
+						Line:      start,
+						Err:       fmt.Errorf("%w: installed from %v", component.ErrSkip, v),
+					})
+				}
+			}
+			// other options like --index-url apply to the whole file
 			continue
 		}
-		if strings.HasPrefix(line, "https://") {
+
+		if loc := requirementsOptionRegexp.FindStringIndex(line); loc != nil {
+			line = strings.TrimSpace(line[:loc[0]])
+		}
+		if isRequirementPath(line) {
+			// a local project or archive, which has no name without building it
 			continue
 		}
-		tokens := strings.Split(line, " ")
-		tokens = strings.Split(tokens[0], "==")
-		c := &component.Module{}
-		c.Name = tokens[0]
-		buf = append(buf, c)
+		add(requirementModule(line, start))
 	}
+	return ms, constraints, scanner.Err()
+}
 
-	return
+// requirementsOption returns the value of an option given as "-r file", "-rfile", "--requirement file" or "--requirement=file".
+func requirementsOption(line, short, long string) (string, bool) {
+	for _, prefix := range []string{long + "=", long, short} {
+		if v, ok := strings.CutPrefix(line, prefix); ok {
+			if prefix == long && v != "" && v[0] != ' ' && v[0] != '\t' {
+				// another option sharing the prefix
+				continue
+			}
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
 }
 
-func (h *RequirementsTXT) SyncWithSource(c component.Component, ctx context.Context) component.Component {
-	switch v := c.(type) {
-	case *component.Module:
-		v = v.SyncWithPypi(ctx, h.HTTPClient)
-		v = v.SyncWithGitHub(ctx, h.GCli)
-		return v
-	default:
-		return v
+// isRequirementPath reports whether a line is a path or url of a project instead of a requirement with a name.
+func isRequirementPath(line string) bool {
+	if urlRequirementRegexp.MatchString(line) {
+		return false
 	}
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "/") || strings.HasPrefix(line, "~") {
+		return true
+	}
+	return strings.Contains(line, "://") || strings.HasSuffix(line, ".whl") || strings.HasSuffix(line, ".tar.gz") || strings.HasSuffix(line, ".zip")
+}
+
+func (h *RequirementsTXT) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
 }
@@ -16,7 +16,21 @@ func Test_RequirementsTXTLookUp(t *testing.T) {
 	m0 := as[0].(*component.Module)
 	assert.Equal(t, m0.Name, "requests")
 	m1 := as[1].(*component.Module)
-	assert.Equal(t, m1.Name, "PyYAML")
+	assert.Equal(t, m1.Name, "pyyaml")
+	assert.Equal(t, m1.Version, "6.0")
 	m2 := as[2].(*component.Module)
 	assert.Equal(t, m2.Name, "pytz")
+	assert.Equal(t, m2.Line, 3)
+
+	as, err = h.LookUp("testdata/pip/requirements.txt")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"requests@2.31.0", "typing-extensions@4.12.2",
+		"django@4.2.7", "flask@>=2.0,<3.0", "zope-interface@", "urllib3@1.26.18",
+		"pip@!", "my-lib@!", "bad-name==@",
+	})
+	assert.Equal(t, as[0].(*component.Module).Line, 3)
+	assert.Equal(t, as[2].(*component.Module).Line, 6)
+	assert.Equal(t, as[5].(*component.Module).Line, 9)
+	assert.ErrorContains(t, as[8].(*component.Module).Err, "bad version specifier")
 }
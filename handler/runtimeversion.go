@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/izziiyt/compaa/component"
+)
+
+// RuntimeVersion reads the runtimes pinned by version manager files like .nvmrc, .python-version and .tool-versions.
+type RuntimeVersion struct {
+	HTTPClient *http.Client
+}
+
+// runtimeFiles maps version files to the endoflife.date product of the runtime they pin.
+// .tool-versions names the runtime on each line instead.
+var runtimeFiles = map[string]string{
+	".nvmrc":          "nodejs",
+	".node-version":   "nodejs",
+	".python-version": "python",
+	".ruby-version":   "ruby",
+	"runtime.txt":     "python",
+}
+
+// toolNames maps asdf and mise plugin names to endoflife.date products.
+var toolNames = map[string]string{
+	"nodejs": "nodejs",
+	"node":   "nodejs",
+	"python": "python",
+	"ruby":   "ruby",
+	"golang": "go",
+	"go":     "go",
+}
+
+var (
+	// pins like 20.11.0, v20, ruby-3.2.2 or python-3.12.1; aliases like lts/* or system are left out
+	runtimeVersionRegexp = regexp.MustCompile(`^(?:v|ruby-|python-)?(\d+(?:\.\d+){0,2})$`)
+	versionNumberRegexp  = regexp.MustCompile(`\d+(?:\.\d+)*`)
+)
+
+func (h *RuntimeVersion) LookUp(path string) (buf []component.Component, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	name := strings.ToLower(filepath.Base(path))
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		runtime, versions := runtimeFiles[name], fields[:1]
+		if name == ".tool-versions" {
+			// nodejs 20.11.0, or python 3.12.1 3.11.7 for several versions
+			runtime, versions = toolNames[fields[0]], fields[1:]
+		}
+		if runtime == "" {
+			continue
+		}
+		for _, v := range versions {
+			if m := runtimeVersionRegexp.FindStringSubmatch(v); m != nil {
+				buf = append(buf, &component.Language{Name: runtime, Version: m[1], Line: n})
+			}
+		}
+	}
+	return buf, scanner.Err()
+}
+
+// rangeFloor returns the release cycle of the lowest version of runtime a range like ">=3.9,<4" or "^18.17.0" allows.
+// The patch is dropped, since a range doesn't pin one. A floor like ">=3" which doesn't tell a cycle is not ok.
+func rangeFloor(runtime, r string) (string, bool) {
+	v := versionNumberRegexp.FindString(r)
+	if v == "" {
+		return "", false
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) < component.CycleDepth(runtime) {
+		return "", false
+	}
+	return strings.Join(parts[:min(len(parts), 2)], "."), true
+}
+
+func (h *RuntimeVersion) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	switch v := c.(type) {
+	case *component.Language:
+		v = v.SyncWithEndOfLife(ctx, h.HTTPClient)
+		return v
+	default:
+		return v
+	}
+}
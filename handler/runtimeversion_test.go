@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_RuntimeVersionLookUp(t *testing.T) {
+	h := &RuntimeVersion{}
+	for _, tt := range []struct {
+		file     string
+		versions []string
+	}{
+		{file: ".nvmrc", versions: []string{"nodejs 20.11.0"}},
+		{file: ".python-version", versions: []string{"python 3.12.1", "python 3.11"}},
+		{file: ".ruby-version", versions: []string{"ruby 3.2.2"}},
+		{file: "runtime.txt", versions: []string{"python 3.12.1"}},
+		{file: ".tool-versions", versions: []string{"nodejs 18.19.0", "python 3.12.1", "python 3.11.7", "go 1.22.0"}},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			as, err := h.LookUp("testdata/runtime/" + tt.file)
+			assert.NilError(t, err)
+			var versions []string
+			for _, a := range as {
+				l := a.(*component.Language)
+				versions = append(versions, l.Name+" "+l.Version)
+			}
+			assert.DeepEqual(t, versions, tt.versions)
+		})
+	}
+
+	as, err := h.LookUp("testdata/runtime/.tool-versions")
+	assert.NilError(t, err)
+	assert.Equal(t, as[3].(*component.Language).Line, 4)
+}
+
+func Test_RangeFloor(t *testing.T) {
+	for _, tt := range []struct {
+		runtime, r, floor string
+		ok                bool
+	}{
+		{runtime: "python", r: ">=3.9,<4", floor: "3.9", ok: true},
+		{runtime: "nodejs", r: "^18.17.0", floor: "18.17", ok: true},
+		{runtime: "nodejs", r: ">=18", floor: "18", ok: true},
+		{runtime: "python", r: ">=3"},
+		{runtime: "php", r: ">=8"},
+		{runtime: "php", r: "*"},
+	} {
+		floor, ok := rangeFloor(tt.runtime, tt.r)
+		assert.Equal(t, ok, tt.ok, tt.r)
+		assert.Equal(t, floor, tt.floor, tt.r)
+	}
+}
@@ -0,0 +1,21 @@
+plugins {
+    id("org.springframework.boot") version "3.2.2"
+}
+
+val jacksonVersion = "2.16.1"
+
+java {
+    toolchain {
+        languageVersion = JavaLanguageVersion.of(21)
+    }
+}
+
+dependencies {
+    implementation(platform("org.springframework.boot:spring-boot-dependencies:3.2.2"))
+    implementation("org.springframework.boot:spring-boot-starter-web")
+    implementation("com.fasterxml.jackson.core:jackson-databind:$jacksonVersion")
+    implementation("org.jetbrains.kotlinx:kotlinx-coroutines-core:${property("kotlinxCoroutinesVersion")}")
+    implementation(libs.guava)
+    // implementation("org.example:commented-out:1.0")
+    testImplementation("org.junit.jupiter:junit-jupiter:5.10.1")
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+)
+
+// UVLock reads uv.lock. The dependencies of the project and its workspace members are the direct dependencies.
+type UVLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+type uvDependency struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+}
+
+func (h *UVLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	g, err := parseUVLock(b)
+	if err != nil {
+		return
+	}
+	return g.modules(h.Transitive), nil
+}
+
+func parseUVLock(b []byte) (*lockGraph, error) {
+	lock := struct {
+		Package []struct {
+			Name                 string                    `toml:"name"`
+			Version              string                    `toml:"version"`
+			Source               map[string]any            `toml:"source"`
+			Dependencies         []uvDependency            `toml:"dependencies"`
+			OptionalDependencies map[string][]uvDependency `toml:"optional-dependencies"`
+			DevDependencies      map[string][]uvDependency `toml:"dev-dependencies"`
+		} `toml:"package"`
+	}{}
+	if _, err := toml.Decode(string(b), &lock); err != nil {
+		return nil, err
+	}
+	lines := tomlArrayLines(b, "package")
+
+	// uv may lock several versions of a package for different markers, dependencies then name the version
+	keys := map[string]string{}
+	for _, p := range lock.Package {
+		name := normalizePyPIName(p.Name)
+		if _, ok := keys[name]; !ok {
+			keys[name] = name + "@" + p.Version
+		}
+	}
+	resolve := func(d uvDependency) string {
+		if d.Version != "" {
+			return normalizePyPIName(d.Name) + "@" + d.Version
+		}
+		return keys[normalizePyPIName(d.Name)]
+	}
+
+	g := newLockGraph(component.EcosystemPyPI)
+	for i, p := range lock.Package {
+		deps := slices.Clone(p.Dependencies)
+		for _, m := range []map[string][]uvDependency{p.OptionalDependencies, p.DevDependencies} {
+			for _, k := range slices.Sorted(maps.Keys(m)) {
+				deps = append(deps, m[k]...)
+			}
+		}
+		var depKeys []string
+		for _, d := range deps {
+			depKeys = append(depKeys, resolve(d))
+		}
+
+		_, editable := p.Source["editable"]
+		_, virtual := p.Source["virtual"]
+		if editable || virtual {
+			// the project and workspace members
+			g.roots = append(g.roots, depKeys...)
+			continue
+		}
+		lp := &lockPackage{name: p.Name, version: p.Version, deps: depKeys}
+		if i < len(lines) {
+			lp.line = lines[i]
+		}
+		for _, k := range []string{"git", "path", "directory", "url"} {
+			if s, ok := p.Source[k].(string); ok {
+				lp.err = fmt.Errorf("%w: installed from %v %v", component.ErrSkip, k, s)
+			}
+		}
+		g.pkgs[normalizePyPIName(p.Name)+"@"+p.Version] = lp
+	}
+	return g, nil
+}
+
+func (h *UVLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithPyPI(c, ctx, h.HTTPClient, h.GCli)
+}
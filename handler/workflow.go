@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// GitHubWorkflow reads the actions and reusable workflows GitHub Actions workflows use. Actions are checked against
+// the repositories they are referenced by, docker:// actions as images, and local actions are skipped.
+type GitHubWorkflow struct {
+	GCli         *github.Client
+	HTTPClient   *http.Client
+	DockerConfig *dockerconfig.Config
+}
+
+// owner/repo@ref, owner/repo/path@ref or owner/repo/.github/workflows/workflow.yml@ref
+var actionReferenceRegexp = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)((?:/[^@]+)?)@(.+)$`)
+
+func (h *GitHubWorkflow) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return
+	}
+	if len(doc.Content) == 0 {
+		return
+	}
+
+	seen := map[string]bool{}
+	add := func(n *yaml.Node) {
+		if n == nil || n.Kind != yaml.ScalarNode || seen[n.Value] {
+			return
+		}
+		seen[n.Value] = true
+		buf = append(buf, actionComponent(n.Value, n.Line))
+	}
+	jobs := yamlMappingValue(doc.Content[0], "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(jobs.Content); i += 2 {
+		job := jobs.Content[i]
+		// jobs calling reusable workflows
+		add(yamlMappingValue(job, "uses"))
+		steps := yamlMappingValue(job, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, step := range steps.Content {
+			add(yamlMappingValue(step, "uses"))
+		}
+	}
+	return
+}
+
+// yamlMappingValue returns the value of key in a mapping node, or nil if n isn't a mapping or has no key.
+func yamlMappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// actionComponent converts the reference of uses into an image for docker://, or into a module of the repository.
+func actionComponent(ref string, line int) component.Component {
+	if image, ok := strings.CutPrefix(ref, "docker://"); ok {
+		c := &component.Image{Line: line}
+		return c.FromRawString(image)
+	}
+	m := &component.Module{Ecosystem: component.EcosystemActions, Name: ref, Line: line}
+	if strings.HasPrefix(ref, "./") {
+		m.Err = fmt.Errorf("%w: local action", component.ErrSkip)
+		return m
+	}
+	r := actionReferenceRegexp.FindStringSubmatch(ref)
+	if r == nil {
+		m.Err = fmt.Errorf("unexpected action reference %v", ref)
+		return m
+	}
+	m.Name, m.Version = r[1]+"/"+r[2]+r[3], r[4]
+	m.GHOrg, m.GHRepo = r[1], r[2]
+	return m
+}
+
+func (h *GitHubWorkflow) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	switch v := c.(type) {
+	case *component.Module:
+		v = v.SyncWithGitHub(ctx, h.GCli)
+		return v
+	case *component.Image:
+		v = v.SyncWithRegistry(ctx, h.HTTPClient, h.DockerConfig)
+		v = v.SyncWithEndOfLife(ctx, h.HTTPClient)
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_GitHubWorkflowLookUp(t *testing.T) {
+	h := &GitHubWorkflow{}
+	as, err := h.LookUp("testdata/workflow/ci.yml")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, describeModules(as), []string{
+		"actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11",
+		"actions/setup-go@v5",
+		"./.github/actions/notify@!",
+		"octo-org/shared/.github/workflows/release.yml@main",
+	})
+	release := as[4].(*component.Module)
+	assert.Equal(t, release.Line, 24)
+	assert.Equal(t, release.GHOrg, "octo-org")
+	assert.Equal(t, release.GHRepo, "shared")
+	setupGo := as[1].(*component.Module)
+	assert.Equal(t, setupGo.Line, 13)
+	assert.Equal(t, setupGo.Ecosystem, component.EcosystemActions)
+	image := as[3].(*component.Image)
+	assert.Equal(t, image.Repository, "alpine")
+	assert.Equal(t, image.Tag, "3.19")
+	assert.Equal(t, image.Line, 18)
+}
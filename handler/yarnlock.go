@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/izziiyt/compaa/component"
+	"gopkg.in/yaml.v3"
+)
+
+// YarnLock reads Yarn classic (v1) and Berry (v2+) lockfiles.
+type YarnLock struct {
+	GCli       *github.Client
+	HTTPClient *http.Client
+	Transitive bool
+}
+
+// yarnEntry is a lockfile entry. descriptors are the "name@range" keys resolved by the entry.
+type yarnEntry struct {
+	descriptors []string
+	version     string
+	line        int
+	deps        map[string]string
+}
+
+func (h *YarnLock) LookUp(path string) (buf []component.Component, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var es []*yarnEntry
+	if bytes.Contains(b, []byte("__metadata:")) {
+		es, err = parseYarnBerryLock(b)
+	} else {
+		es, err = parseYarnClassicLock(b)
+	}
+	if err != nil {
+		return
+	}
+
+	// classic lockfiles have no entry for the project itself
+	var roots map[string]string
+	if pj, err := os.ReadFile(filepath.Join(filepath.Dir(path), "package.json")); err == nil {
+		if ps, err := parsePackageJSON(pj); err == nil {
+			roots = map[string]string{}
+			for _, p := range ps {
+				roots[p.Name] = p.Version
+			}
+		}
+	}
+	return newYarnGraph(es, roots).modules(h.Transitive), nil
+}
+
+func newYarnGraph(es []*yarnEntry, roots map[string]string) *lockGraph {
+	g := newLockGraph(component.EcosystemNPM)
+	byDescriptor := map[string]string{}
+	for _, e := range es {
+		key := e.descriptors[0]
+		for _, d := range e.descriptors {
+			byDescriptor[d] = key
+		}
+	}
+	resolve := func(name, rng string) (string, bool) {
+		for _, d := range []string{name + "@" + rng, name + "@npm:" + rng} {
+			if k, ok := byDescriptor[d]; ok {
+				return k, true
+			}
+		}
+		return "", false
+	}
+
+	for _, e := range es {
+		key := e.descriptors[0]
+		name, rng := splitDescriptor(key)
+		if strings.HasPrefix(rng, "workspace:") {
+			// berry lists workspaces, including the project itself, as entries
+			for _, d := range slices.Sorted(maps.Keys(e.deps)) {
+				if k, ok := resolve(d, e.deps[d]); ok {
+					g.roots = append(g.roots, k)
+				}
+			}
+			continue
+		}
+		p := &lockPackage{name: name, version: e.version, line: e.line}
+		for _, d := range slices.Sorted(maps.Keys(e.deps)) {
+			if k, ok := resolve(d, e.deps[d]); ok {
+				p.deps = append(p.deps, k)
+			}
+		}
+		g.pkgs[key] = p
+	}
+	for _, name := range slices.Sorted(maps.Keys(roots)) {
+		if k, ok := resolve(name, roots[name]); ok {
+			g.roots = append(g.roots, k)
+		}
+	}
+	if len(g.roots) == 0 {
+		// without package.json, packages nothing depends on are taken as direct dependencies
+		g.orphanRoots()
+	}
+	return g
+}
+
+// splitDescriptor splits "@scope/name@^1.0.0" into "@scope/name" and "^1.0.0".
+func splitDescriptor(d string) (name, rng string) {
+	i := strings.Index(d[1:], "@")
+	if i < 0 {
+		return d, ""
+	}
+	return d[:i+1], d[i+2:]
+}
+
+func parseYarnClassicLock(b []byte) (es []*yarnEntry, err error) {
+	var e *yarnEntry
+	inDeps := false
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		line = strings.TrimSpace(line)
+		switch {
+		case indent == 0:
+			e = &yarnEntry{line: n, deps: map[string]string{}}
+			for _, d := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				e.descriptors = append(e.descriptors, unquote(strings.TrimSpace(d)))
+			}
+			es = append(es, e)
+			inDeps = false
+		case e == nil:
+			continue
+		case indent == 2:
+			key, value, _ := strings.Cut(line, " ")
+			inDeps = key == "dependencies:" || key == "optionalDependencies:"
+			if key == "version" {
+				e.version = unquote(value)
+			}
+		case indent == 4 && inDeps:
+			name, rng, _ := strings.Cut(line, " ")
+			e.deps[unquote(name)] = unquote(rng)
+		}
+	}
+	return es, scanner.Err()
+}
+
+func parseYarnBerryLock(b []byte) (es []*yarnEntry, err error) {
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(b, doc); err != nil {
+		return
+	}
+	if len(doc.Content) == 0 {
+		return
+	}
+	m := doc.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if k.Value == "__metadata" {
+			continue
+		}
+		entry := struct {
+			Version              string            `yaml:"version"`
+			Dependencies         map[string]string `yaml:"dependencies"`
+			OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+		}{}
+		if err = v.Decode(&entry); err != nil {
+			return
+		}
+		e := &yarnEntry{line: k.Line, version: entry.Version, deps: map[string]string{}}
+		for _, d := range strings.Split(k.Value, ",") {
+			e.descriptors = append(e.descriptors, strings.TrimSpace(d))
+		}
+		for name, rng := range entry.Dependencies {
+			e.deps[name] = rng
+		}
+		for name, rng := range entry.OptionalDependencies {
+			e.deps[name] = rng
+		}
+		es = append(es, e)
+	}
+	return
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+func (h *YarnLock) SyncWithSource(c component.Component, ctx context.Context) component.Component {
+	return syncWithNPM(c, ctx, h.HTTPClient, h.GCli)
+}
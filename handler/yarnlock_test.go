@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_YarnLockLookUp(t *testing.T) {
+	h := &YarnLock{Transitive: true}
+	as, err := h.LookUp("testdata/yarn.lock")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 6)
+
+	m0 := as[0].(*component.Module)
+	assert.Equal(t, m0.Name, "abc")
+	assert.Equal(t, m0.Line, 5)
+	assert.Assert(t, !m0.Indirect)
+	m5 := as[5].(*component.Module)
+	assert.Equal(t, m5.Name, "base64-js")
+	assert.Equal(t, m5.Version, "1.5.1")
+	assert.DeepEqual(t, m5.Path, []string{"aws-sdk", "buffer"})
+}
+
+func Test_YarnLockLookUpBerry(t *testing.T) {
+	h := &YarnLock{Transitive: true}
+	as, err := h.LookUp("testdata/berry/yarn.lock")
+	assert.NilError(t, err)
+	assert.Equal(t, len(as), 3)
+
+	m0 := as[0].(*component.Module)
+	assert.Equal(t, m0.Name, "@babel/code-frame")
+	assert.Equal(t, m0.Version, "7.22.13")
+	assert.Equal(t, m0.Line, 8)
+	m2 := as[2].(*component.Module)
+	assert.Equal(t, m2.Name, "chalk")
+	assert.DeepEqual(t, m2.Path, []string{"@babel/code-frame"})
+}
@@ -2,24 +2,56 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
 
 	"github.com/izziiyt/compaa/component"
 	"github.com/izziiyt/compaa/handler"
+	"github.com/izziiyt/compaa/report"
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+)
+
+// exit codes let pipelines tell an unhealthy dependency tree from a broken run
+const (
+	exitOK = iota
+	exitFailure
+	exitWarn
+	exitError
+)
+
+const policyFileName = ".compaa.yaml"
+
+const (
+	failOnWarn  = "warn"
+	failOnError = "error"
+	failOnNever = "never"
 )
 
 var (
-	rd    = flag.Int("d", 730, "recent days. used to determine log level")
-	token = flag.String("t", "", "github token. recommended to set for sufficient github api rate limit, or set GITHUB_TOKEN env var")
+	rd             = flag.Int("d", 730, "recent days. used to determine log level. overrides the default stale days of "+policyFileName)
+	token          = flag.String("t", "", "github token. recommended to set for sufficient github api rate limit, or set GITHUB_TOKEN env var")
+	format         = flag.String("format", report.FormatText, "output format. text, json or sarif")
+	showSuppressed = flag.Bool("show-suppressed", false, "list findings suppressed by ignore rules of "+policyFileName)
+	baselineFile   = flag.String("baseline", "", "report only findings which are not in this baseline file. \"baseline write\" writes to it (default "+report.DefaultBaselineFile+")")
+	transitive     = flag.Bool("transitive", false, "include transitive dependencies of lockfiles")
+	failOn         = flag.String("fail-on", failOnNever, "exit with non-zero code when findings reach this severity. warn, error or never")
 )
 
 func main() {
-	flag.Parse()
+	os.Exit(run())
+}
+
+func run() int {
+	// bad flags exit with exitFailure rather than 2, which is exitWarn
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		return parseExitCode(err)
+	}
 	args := flag.Args()
 
 	transport := NewCacheTransport()
@@ -28,10 +60,40 @@ func main() {
 	if len(args) > 0 && args[0] == "flush" {
 		if err := transport.Cache.Clear(); err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to clear cache:", err)
-			os.Exit(1)
+			return exitFailure
 		}
 		fmt.Println("Cache cleared successfully.")
-		return
+		return exitOK
+	}
+
+	writeBaseline := false
+	if len(args) > 1 && args[0] == "baseline" && args[1] == "write" {
+		// flags may follow the subcommand
+		if err := flag.CommandLine.Parse(args[2:]); err != nil {
+			return parseExitCode(err)
+		}
+		args = flag.Args()
+		writeBaseline = true
+	}
+
+	var diffRevs []string
+	if len(args) > 0 && args[0] == "diff" {
+		if err := flag.CommandLine.Parse(args[1:]); err != nil {
+			return parseExitCode(err)
+		}
+		diffRevs = append(flag.Args(), "HEAD")
+		if len(flag.Args()) < 1 || len(flag.Args()) > 2 {
+			fmt.Fprintln(os.Stderr, "usage: compaa diff <base-rev> [<head-rev>]")
+			return exitFailure
+		}
+		args = nil
+	}
+
+	switch *failOn {
+	case failOnWarn, failOnError, failOnNever:
+	default:
+		fmt.Fprintln(os.Stderr, "unsupported fail-on: "+*failOn)
+		return exitFailure
 	}
 
 	path := "."
@@ -39,34 +101,186 @@ func main() {
 		path = args[0]
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			fmt.Fprintln(os.Stderr, path+" not found")
-			os.Exit(1)
+			return exitFailure
 		}
 	}
+	policy, err := loadPolicy(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
 	ctx := context.Background()
-	wc := &component.DefaultWarnCondition
-	wc.RecentDays = *rd
 	if *token == "" {
 		*token = os.Getenv("GITHUB_TOKEN")
 	}
 	if *token == "" {
-		fmt.Println("WARN: recommended to use github token. see `compaa -h`")
+		fmt.Fprintln(os.Stderr, "WARN: recommended to use github token. see `compaa -h`")
 	}
-	transport = NewCacheTransport()
-	defer transport.Close()
-	r := NewRouter(*token, transport)
-	err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+	dockerConfig, err := dockerconfig.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "WARN: images are read anonymously.", err)
+	}
+	r := NewRouter(*token, transport, *transitive, dockerConfig)
+
+	if writeBaseline {
+		return runBaselineWrite(ctx, r, policy, path)
+	}
+
+	w, err := report.NewWriter(*format, os.Stdout, report.Options{ShowSuppressed: *showSuppressed})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	var bl *report.Baseline
+	if *baselineFile != "" {
+		if bl, err = report.LoadBaseline(*baselineFile, path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+	if diffRevs != nil {
+		worst, failed, err := runDiff(ctx, r, policy, bl, diffRevs[0], diffRevs[1], w)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitCode(worst, failed)
+	}
+
+	worst := component.VerdictOK
+	failed := false
+	err = scan(ctx, r, policy, path, func(m *report.Manifest) error {
+		if bl != nil {
+			bl.Filter(m)
+		}
+		worst = max(worst, m.Verdict())
+		failed = failed || m.Error != ""
+		return w.Write(m)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	if bl != nil {
+		for _, e := range bl.Stale() {
+			fmt.Fprintf(os.Stderr, "baseline entry no longer reproduces: %v %v %v %v\n", e.Path, e.Type, e.Name, e.Rule)
+		}
+	}
+	return exitCode(worst, failed)
+}
+
+func runBaselineWrite(ctx context.Context, r *Router, policy *component.Policy, root string) int {
+	bl := report.NewBaseline(root)
+	failed := false
+	err := scan(ctx, r, policy, root, func(m *report.Manifest) error {
+		failed = failed || m.Error != ""
+		if m.Error != "" {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", m.Path, m.Error)
+		}
+		bl.Add(m)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	out := *baselineFile
+	if out == "" {
+		out = report.DefaultBaselineFile
+	}
+	if err := bl.Save(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Printf("%v baseline entries written to %v\n", len(bl.Entries()), out)
+	if failed {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func scan(ctx context.Context, r *Router, policy *component.Policy, root string, fn func(m *report.Manifest) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if d.IsDir() && excludedPatterns(d.Name()) {
 			return filepath.SkipDir
 		}
-		if h := r.Route(d.Name()); h != nil {
-			handler.Handle(h, ctx, path, wc)
+		if h := r.Route(path); h != nil {
+			return fn(handler.Handle(h, ctx, path, policy))
 		}
 		return nil
 	})
+}
+
+// loadPolicy reads the nearest policy file found by walking up from root.
+// The -d flag takes precedence over the file when it is set explicitly.
+func loadPolicy(root string) (*component.Policy, error) {
+	policy := component.NewPolicy()
+	path, err := findPolicy(root)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return nil, err
+	}
+	if path != "" {
+		if policy, err = component.LoadPolicy(path); err != nil {
+			return nil, err
+		}
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "d" {
+			policy.StaleDays[component.EcosystemDefault] = *rd
+			policy.EOLSoonDays = *rd
+		}
+	})
+	return policy, nil
+}
+
+func findPolicy(root string) (string, error) {
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		path := filepath.Join(dir, policyFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// parseExitCode returns the exit code for an error of parsing flags. -h is not a failure.
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitFailure
+}
+
+func exitCode(worst component.Verdict, failed bool) int {
+	if failed {
+		return exitFailure
+	}
+	switch {
+	case *failOn == failOnNever:
+		return exitOK
+	case worst >= component.VerdictError:
+		return exitError
+	case worst >= component.VerdictWarn && *failOn == failOnWarn:
+		return exitWarn
 	}
+	return exitOK
 }
 
 func excludedPatterns(path string) bool {
@@ -77,10 +291,6 @@ func excludedPatterns(path string) bool {
 		".vscode",
 		".idea",
 	}
-	for _, p := range excludePatterns {
-		if strings.Contains(path, p) {
-			return true
-		}
-	}
-	return false
+	// names match exactly, as .git is a prefix of .github
+	return slices.Contains(excludePatterns, path)
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_ExitCode(t *testing.T) {
+	defer func(v string) { *failOn = v }(*failOn)
+	for _, tt := range []struct {
+		failOn string
+		worst  component.Verdict
+		failed bool
+		code   int
+	}{
+		{failOn: failOnNever, worst: component.VerdictOK, code: exitOK},
+		{failOn: failOnNever, worst: component.VerdictError, code: exitOK},
+		{failOn: failOnNever, worst: component.VerdictOK, failed: true, code: exitFailure},
+		{failOn: failOnWarn, worst: component.VerdictOK, code: exitOK},
+		{failOn: failOnWarn, worst: component.VerdictInfo, code: exitOK},
+		{failOn: failOnWarn, worst: component.VerdictWarn, code: exitWarn},
+		{failOn: failOnWarn, worst: component.VerdictError, code: exitError},
+		{failOn: failOnWarn, worst: component.VerdictWarn, failed: true, code: exitFailure},
+		{failOn: failOnError, worst: component.VerdictInfo, code: exitOK},
+		{failOn: failOnError, worst: component.VerdictWarn, code: exitOK},
+		{failOn: failOnError, worst: component.VerdictError, code: exitError},
+		{failOn: failOnError, worst: component.VerdictError, failed: true, code: exitFailure},
+	} {
+		*failOn = tt.failOn
+		assert.Equal(t, exitCode(tt.worst, tt.failed), tt.code, fmt.Sprintf("%v %v %v", tt.failOn, tt.worst, tt.failed))
+	}
+}
+
+func Test_ParseExitCode(t *testing.T) {
+	assert.Equal(t, parseExitCode(flag.ErrHelp), exitOK)
+	assert.Equal(t, parseExitCode(fmt.Errorf("flag provided but not defined: -x")), exitFailure)
+}
+
+func Test_ExcludedPatterns(t *testing.T) {
+	assert.Assert(t, excludedPatterns(".git"))
+	assert.Assert(t, excludedPatterns("node_modules"))
+	assert.Assert(t, !excludedPatterns(".github"))
+	assert.Assert(t, !excludedPatterns("vendors"))
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/izziiyt/compaa/component"
+)
+
+const DefaultBaselineFile = ".compaa-baseline.json"
+
+// BaselineEntry identifies an accepted finding. Path is relative to the scan root.
+type BaselineEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Rule string `json:"rule"`
+}
+
+// Baseline is a snapshot of accepted findings, so that later scans report only new ones.
+// Only rule violations are recorded, errors are always reported.
+type Baseline struct {
+	Root    string
+	entries map[BaselineEntry]bool
+}
+
+func NewBaseline(root string) *Baseline {
+	return &Baseline{
+		Root:    root,
+		entries: make(map[BaselineEntry]bool),
+	}
+}
+
+func LoadBaseline(path, root string) (*Baseline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := struct {
+		Entries []BaselineEntry `json:"entries"`
+	}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	bl := NewBaseline(root)
+	for _, e := range doc.Entries {
+		bl.entries[e] = false
+	}
+	return bl, nil
+}
+
+func (bl *Baseline) Save(path string) error {
+	doc := struct {
+		Entries []BaselineEntry `json:"entries"`
+	}{
+		Entries: bl.Entries(),
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Entries returns every entry of the baseline in a stable order.
+func (bl *Baseline) Entries() []BaselineEntry {
+	es := []BaselineEntry{}
+	for e := range bl.entries {
+		es = append(es, e)
+	}
+	sortEntries(es)
+	return es
+}
+
+// Add records the rule violations of m.
+func (bl *Baseline) Add(m *Manifest) {
+	for _, r := range m.Components {
+		for _, f := range r.Findings {
+			if f.Rule != "" {
+				bl.entries[bl.entry(m.Path, r, &f)] = false
+			}
+		}
+	}
+}
+
+// Filter moves the findings of m which are in the baseline into Result.Baselined.
+func (bl *Baseline) Filter(m *Manifest) {
+	for _, r := range m.Components {
+		r.Baselined = append(r.Baselined, r.Remove(func(f *component.Finding) bool {
+			e := bl.entry(m.Path, r, f)
+			if _, ok := bl.entries[e]; !ok {
+				return false
+			}
+			bl.entries[e] = true
+			return true
+		})...)
+	}
+}
+
+// Stale returns the entries which did not match any finding since the baseline was loaded.
+func (bl *Baseline) Stale() []BaselineEntry {
+	var es []BaselineEntry
+	for e, seen := range bl.entries {
+		if !seen {
+			es = append(es, e)
+		}
+	}
+	sortEntries(es)
+	return es
+}
+
+func (bl *Baseline) entry(path string, r *component.Result, f *component.Finding) BaselineEntry {
+	if rel, err := filepath.Rel(bl.Root, path); err == nil {
+		path = rel
+	}
+	return BaselineEntry{
+		Path: filepath.ToSlash(path),
+		Type: r.Type,
+		Name: r.Name,
+		Rule: f.Rule,
+	}
+}
+
+func sortEntries(es []BaselineEntry) {
+	slices.SortFunc(es, func(a, b BaselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Rule, b.Rule),
+		)
+	})
+}
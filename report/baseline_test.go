@@ -0,0 +1,44 @@
+package report
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func newTestManifest() *Manifest {
+	archived := &component.Result{Type: component.TypeModule, Name: "github.com/pkg/errors"}
+	archived.Add(component.RuleArchived, component.VerdictWarn, "github.com/pkg/errors is archived")
+	stale := &component.Result{Type: component.TypeModule, Name: "github.com/jinzhu/gorm"}
+	stale.Add(component.RuleStalePush, component.VerdictWarn, "github.com/jinzhu/gorm last push isn't recent")
+	broken := &component.Result{Type: component.TypeModule, Name: "example.com/broken"}
+	broken.Add("", component.VerdictError, "example.com/broken not found")
+	return &Manifest{
+		Path:       "root/svc/go.mod",
+		Components: []*component.Result{archived, stale, broken},
+	}
+}
+
+func Test_Baseline(t *testing.T) {
+	bl := NewBaseline("root")
+	bl.Add(newTestManifest())
+	es := bl.Entries()
+	assert.Equal(t, len(es), 2)
+	assert.Equal(t, es[0], BaselineEntry{Path: "svc/go.mod", Type: "module", Name: "github.com/jinzhu/gorm", Rule: component.RuleStalePush})
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	assert.NilError(t, bl.Save(path))
+	bl, err := LoadBaseline(path, "root")
+	assert.NilError(t, err)
+
+	m := newTestManifest()
+	m.Components = m.Components[1:]
+	bl.Filter(m)
+	assert.Equal(t, m.Baselined(), 1)
+	assert.Equal(t, m.Verdict(), component.VerdictError)
+	assert.DeepEqual(t, bl.Stale(), []BaselineEntry{
+		{Path: "svc/go.mod", Type: "module", Name: "github.com/pkg/errors", Rule: component.RuleArchived},
+	})
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSON collects every manifest and writes a single document on Close.
+type JSON struct {
+	W          io.Writer
+	manifests  []*Manifest
+	suppressed int
+	baselined  int
+}
+
+func (j *JSON) Write(m *Manifest) error {
+	j.manifests = append(j.manifests, m)
+	j.suppressed += m.Suppressed()
+	j.baselined += m.Baselined()
+	return nil
+}
+
+func (j *JSON) Close() error {
+	doc := struct {
+		Manifests  []*Manifest `json:"manifests"`
+		Suppressed int         `json:"suppressed"`
+		Baselined  int         `json:"baselined"`
+	}{
+		Manifests:  j.manifests,
+		Suppressed: j.suppressed,
+		Baselined:  j.baselined,
+	}
+	if doc.Manifests == nil {
+		doc.Manifests = []*Manifest{}
+	}
+	enc := json.NewEncoder(j.W)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
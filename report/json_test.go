@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+)
+
+func Test_JSON(t *testing.T) {
+	m := newTestManifest()
+	m.Components[0].Ecosystem = component.EcosystemGo
+	m.Components[0].Line = 5
+	m.Components[0].GHOrg, m.Components[0].GHRepo = "pkg", "errors"
+	m.Components[0].Archived = true
+	m.Components[0].LastPush = time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	m.Components[1].Suppressed = []component.Suppression{{
+		Finding: component.Finding{Rule: component.RuleStalePush, Verdict: component.VerdictWarn, Message: "stale"},
+		Reason:  "kept deliberately",
+	}}
+	broken := &Manifest{Path: "root/package.json", Error: "unexpected end of JSON input"}
+
+	b := &bytes.Buffer{}
+	w := &JSON{W: b}
+	assert.NilError(t, w.Write(m))
+	assert.NilError(t, w.Write(broken))
+	assert.NilError(t, w.Close())
+
+	doc := struct {
+		Manifests []struct {
+			Path       string                   `json:"path"`
+			Error      string                   `json:"error"`
+			Components []map[string]interface{} `json:"components"`
+		} `json:"manifests"`
+		Suppressed int `json:"suppressed"`
+		Baselined  int `json:"baselined"`
+	}{}
+	assert.NilError(t, json.Unmarshal(b.Bytes(), &doc))
+	assert.Equal(t, len(doc.Manifests), 2)
+	assert.Equal(t, doc.Suppressed, 1)
+	assert.Equal(t, doc.Baselined, 0)
+
+	assert.Equal(t, doc.Manifests[0].Path, "root/svc/go.mod")
+	archived := doc.Manifests[0].Components[0]
+	assert.Equal(t, archived["type"], "module")
+	assert.Equal(t, archived["name"], "github.com/pkg/errors")
+	assert.Equal(t, archived["ecosystem"], "go")
+	assert.Equal(t, archived["line"], float64(5))
+	assert.Equal(t, archived["github_org"], "pkg")
+	assert.Equal(t, archived["github_repo"], "errors")
+	assert.Equal(t, archived["last_push"], "2021-12-01T00:00:00Z")
+	assert.Equal(t, archived["archived"], true)
+	assert.Equal(t, archived["verdict"], "warn")
+	assert.Equal(t, archived["reason"], "github.com/pkg/errors is archived")
+	assert.DeepEqual(t, archived["findings"], []interface{}{
+		map[string]interface{}{"rule": "archived-repo", "verdict": "warn", "message": "github.com/pkg/errors is archived"},
+	})
+	// zero dates are omitted rather than written as 0001-01-01
+	_, ok := archived["eol_date"]
+	assert.Assert(t, !ok)
+
+	suppressed := doc.Manifests[0].Components[1]["suppressed"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, suppressed["rule"], "stale-push")
+	assert.Equal(t, suppressed["reason"], "kept deliberately")
+	assert.Equal(t, doc.Manifests[0].Components[2]["verdict"], "error")
+
+	assert.Equal(t, doc.Manifests[1].Error, "unexpected end of JSON input")
+	assert.Equal(t, len(doc.Manifests[1].Components), 0)
+}
+
+func Test_JSONEmpty(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := &JSON{W: b}
+	assert.NilError(t, w.Close())
+	assert.Equal(t, b.String(), "{\n  \"manifests\": [],\n  \"suppressed\": 0,\n  \"baselined\": 0\n}\n")
+}
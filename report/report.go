@@ -0,0 +1,71 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/izziiyt/compaa/component"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Manifest is the outcome of scanning a single manifest file.
+type Manifest struct {
+	Path       string              `json:"path"`
+	Error      string              `json:"error,omitempty"`
+	Components []*component.Result `json:"components"`
+}
+
+// Verdict returns the worst verdict across the components of the manifest.
+func (m *Manifest) Verdict() component.Verdict {
+	v := component.VerdictOK
+	for _, r := range m.Components {
+		v = max(v, r.Verdict)
+	}
+	return v
+}
+
+// Suppressed returns the number of findings silenced by ignore entries.
+func (m *Manifest) Suppressed() int {
+	n := 0
+	for _, r := range m.Components {
+		n += len(r.Suppressed)
+	}
+	return n
+}
+
+// Baselined returns the number of findings hidden by the baseline.
+func (m *Manifest) Baselined() int {
+	n := 0
+	for _, r := range m.Components {
+		n += len(r.Baselined)
+	}
+	return n
+}
+
+type Writer interface {
+	Write(m *Manifest) error
+	Close() error
+}
+
+type Options struct {
+	// ShowSuppressed lists suppressed findings in the text output.
+	ShowSuppressed bool
+}
+
+func NewWriter(format string, w io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case FormatText:
+		return &Text{W: w, ShowSuppressed: opts.ShowSuppressed}, nil
+	case FormatJSON:
+		return &JSON{W: w}, nil
+	case FormatSARIF:
+		return &SARIF{W: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %v", format)
+	}
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/izziiyt/compaa/component"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "compaa"
+	toolURI      = "https://github.com/izziiyt/compaa"
+)
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+var sarifRules = []sarifRule{
+	newSarifRule(component.RuleArchived, "ArchivedRepository", "The source repository of the dependency is archived"),
+	newSarifRule(component.RuleStalePush, "StalePush", "The source repository of the dependency has not been pushed recently"),
+	newSarifRule(component.RuleStaleImage, "StaleImage", "The container image tag has not been updated recently"),
+	newSarifRule(component.RuleLanguageEOL, "LanguageEOL", "The language runtime is end of life"),
+	newSarifRule(component.RuleLanguageEOLSoon, "LanguageEOLSoon", "The language runtime will soon be end of life"),
+	newSarifRule(component.RuleNotLatestPatch, "NotLatestPatch", "The language runtime is not on the latest patch release"),
+	newSarifRule(component.RuleDigestDrift, "DigestDrift", "The pinned image digest is no longer the one of a live tag"),
+	newSarifRule(component.RuleImageEOL, "ImageEOL", "The container image is built on an end of life release"),
+	newSarifRule(component.RuleImageEOLSoon, "ImageEOLSoon", "The container image is built on a release which will soon be end of life"),
+	newSarifRule(component.RuleExpiredIgnore, "ExpiredIgnore", "An ignore entry of the policy has expired"),
+	newSarifRule(component.RuleUnpinnedAction, "UnpinnedAction", "The GitHub Action is referenced by a ref which can be moved, not a full commit SHA"),
+}
+
+func newSarifRule(id, name, desc string) sarifRule {
+	r := sarifRule{ID: id, Name: name, ShortDescription: sarifMessage{Text: desc}}
+	r.DefaultConfig.Level = "warning"
+	if rc, ok := component.DefaultPolicy.Rules[id]; ok {
+		r.DefaultConfig.Level = sarifLevel(rc.Severity)
+	}
+	return r
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// SARIF collects every manifest and writes a SARIF 2.1.0 log on Close.
+// Rule violations become results, errors and notes become tool execution notifications.
+type SARIF struct {
+	W             io.Writer
+	results       []sarifResult
+	notifications []sarifNotification
+	// failed is set when a manifest couldn't be read or a component couldn't be synced, so the scan is partial
+	failed bool
+}
+
+func (s *SARIF) Write(m *Manifest) error {
+	if m.Error != "" {
+		s.failed = true
+		s.notifications = append(s.notifications, sarifNotification{
+			Level:     "error",
+			Message:   sarifMessage{Text: m.Error},
+			Locations: []sarifLocation{newSarifLocation(m.Path, 0)},
+		})
+	}
+	for _, r := range m.Components {
+		for _, f := range r.Findings {
+			loc := newSarifLocation(m.Path, r.Line)
+			idx := sarifRuleIndex(f.Rule)
+			if idx < 0 {
+				s.failed = s.failed || f.Verdict == component.VerdictError
+				s.notifications = append(s.notifications, sarifNotification{
+					Level:     sarifLevel(f.Verdict),
+					Message:   sarifMessage{Text: f.Message},
+					Locations: []sarifLocation{loc},
+				})
+				continue
+			}
+			s.results = append(s.results, sarifResult{
+				RuleID:    f.Rule,
+				RuleIndex: idx,
+				Level:     sarifLevel(f.Verdict),
+				Message:   sarifMessage{Text: f.Message},
+				Locations: []sarifLocation{loc},
+			})
+		}
+		for _, f := range r.Suppressed {
+			idx := sarifRuleIndex(f.Rule)
+			if idx < 0 {
+				continue
+			}
+			s.results = append(s.results, sarifResult{
+				RuleID:       f.Rule,
+				RuleIndex:    idx,
+				Level:        sarifLevel(f.Verdict),
+				Message:      sarifMessage{Text: f.Message},
+				Locations:    []sarifLocation{newSarifLocation(m.Path, r.Line)},
+				Suppressions: []sarifSuppression{{Kind: "external", Justification: f.Reason}},
+			})
+		}
+	}
+	return nil
+}
+
+func (s *SARIF) Close() error {
+	type driver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	type invocation struct {
+		ExecutionSuccessful        bool                `json:"executionSuccessful"`
+		ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	}
+	type run struct {
+		Tool struct {
+			Driver driver `json:"driver"`
+		} `json:"tool"`
+		Invocations []invocation  `json:"invocations"`
+		Results     []sarifResult `json:"results"`
+	}
+
+	r := run{
+		Invocations: []invocation{{ExecutionSuccessful: !s.failed, ToolExecutionNotifications: s.notifications}},
+		Results:     s.results,
+	}
+	r.Tool.Driver = driver{Name: toolName, InformationURI: toolURI, Rules: sarifRules}
+	if r.Results == nil {
+		r.Results = []sarifResult{}
+	}
+
+	doc := struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []run  `json:"runs"`
+	}{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []run{r},
+	}
+	enc := json.NewEncoder(s.W)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func sarifRuleIndex(id string) int {
+	for i, r := range sarifRules {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func sarifLevel(v component.Verdict) string {
+	switch v {
+	case component.VerdictError:
+		return "error"
+	case component.VerdictWarn:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func newSarifLocation(path string, line int) sarifLocation {
+	l := sarifLocation{}
+	uri := filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		uri = "file://" + uri
+	} else {
+		uri = strings.TrimPrefix(uri, "./")
+	}
+	l.PhysicalLocation.ArtifactLocation.URI = uri
+	if line > 0 {
+		l.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return l
+}
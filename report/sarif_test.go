@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/izziiyt/compaa/component"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func Test_SARIF(t *testing.T) {
+	m := newTestManifest()
+	m.Components[0].Line = 5
+	m.Components[1].Line = 7
+	m.Components[1].Suppressed = []component.Suppression{{
+		Finding: component.Finding{Rule: component.RuleArchived, Verdict: component.VerdictWarn, Message: "github.com/jinzhu/gorm is archived"},
+		Reason:  "kept deliberately",
+	}}
+
+	b := &bytes.Buffer{}
+	w := &SARIF{W: b}
+	assert.NilError(t, w.Write(m))
+	assert.NilError(t, w.Write(&Manifest{Path: "root/package.json", Error: "unexpected end of JSON input"}))
+	assert.NilError(t, w.Close())
+	golden.Assert(t, b.String(), "sarif.golden")
+}
+
+func Test_SARIFRuleLevels(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NilError(t, (&SARIF{W: b}).Close())
+	doc := struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []sarifRule `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+		} `json:"runs"`
+	}{}
+	assert.NilError(t, json.Unmarshal(b.Bytes(), &doc))
+	levels := map[string]string{}
+	for _, r := range doc.Runs[0].Tool.Driver.Rules {
+		levels[r.ID] = r.DefaultConfig.Level
+	}
+	// the default level follows the default severity of the policy
+	assert.Equal(t, levels[component.RuleArchived], "warning")
+	assert.Equal(t, levels[component.RuleUnpinnedAction], "note")
+	assert.Equal(t, levels[component.RuleExpiredIgnore], "warning")
+	assert.Equal(t, len(levels), len(sarifRules))
+}
+
+func Test_SARIFExecutionSuccessful(t *testing.T) {
+	clean := newTestManifest()
+	clean.Components = clean.Components[:2]
+	for _, tt := range []struct {
+		name       string
+		manifest   *Manifest
+		successful bool
+	}{
+		{name: "clean", manifest: clean, successful: true},
+		{name: "unreadable manifest", manifest: &Manifest{Path: "root/package.json", Error: "unexpected end of JSON input"}},
+		{name: "unsynced component", manifest: newTestManifest()},
+	} {
+		b := &bytes.Buffer{}
+		w := &SARIF{W: b}
+		assert.NilError(t, w.Write(tt.manifest))
+		assert.NilError(t, w.Close())
+		doc := struct {
+			Runs []struct {
+				Invocations []struct {
+					ExecutionSuccessful bool `json:"executionSuccessful"`
+				} `json:"invocations"`
+			} `json:"runs"`
+		}{}
+		assert.NilError(t, json.Unmarshal(b.Bytes(), &doc))
+		assert.Equal(t, doc.Runs[0].Invocations[0].ExecutionSuccessful, tt.successful, tt.name)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// Text writes manifests as they arrive in the human readable tree format.
+type Text struct {
+	W              io.Writer
+	ShowSuppressed bool
+	suppressed     int
+	baselined      int
+}
+
+func (t *Text) Write(m *Manifest) error {
+	fmt.Fprintf(t.W, "%v\n", m.Path)
+	logger := &textLogger{w: t.W}
+	if m.Error != "" {
+		logger.Error("├ LookUp error: %v\n", m.Error)
+	}
+	for _, r := range m.Components {
+		r.Logging(logger)
+		if t.ShowSuppressed {
+			for _, s := range r.Suppressed {
+				logger.Debug("├ SUPPRESSED: %v (%v)\n", s.Message, s.Reason)
+			}
+		}
+	}
+	t.suppressed += m.Suppressed()
+	t.baselined += m.Baselined()
+	return nil
+}
+
+func (t *Text) Close() error {
+	if t.suppressed > 0 {
+		fmt.Fprintf(t.W, "%v findings suppressed by ignore rules\n", t.suppressed)
+	}
+	if t.baselined > 0 {
+		fmt.Fprintf(t.W, "%v findings hidden by baseline\n", t.baselined)
+	}
+	return nil
+}
+
+type textLogger struct {
+	w io.Writer
+}
+
+func (l *textLogger) Error(format string, a ...interface{}) {
+	color.New(color.FgRed).Fprintf(l.w, format, a...)
+}
+
+func (l *textLogger) Warn(format string, a ...interface{}) {
+	color.New(color.FgYellow).Fprintf(l.w, format, a...)
+}
+
+func (l *textLogger) Info(format string, a ...interface{}) {
+	color.New(color.FgGreen).Fprintf(l.w, format, a...)
+}
+
+func (l *textLogger) Debug(format string, a ...interface{}) {
+	color.New(color.FgWhite).Fprintf(l.w, format, a...)
+}
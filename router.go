@@ -3,7 +3,9 @@ package main
 import (
 	"github.com/google/go-github/v60/github"
 	"github.com/izziiyt/compaa/handler"
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
 	"net/http"
+	"path/filepath"
 	"strings"
 )
 
@@ -13,30 +15,145 @@ type Router struct {
 	dockerfile      *handler.Dockerfile
 	requirementstxt *handler.RequirementsTXT
 	gemfile         *handler.GemFile
+	packagelockjson *handler.PackageLockJSON
+	yarnlock        *handler.YarnLock
+	pnpmlock        *handler.PNPMLock
+	runtimeversion  *handler.RuntimeVersion
+	pyproject       *handler.PyProject
+	poetrylock      *handler.PoetryLock
+	pipfile         *handler.Pipfile
+	pipfilelock     *handler.PipfileLock
+	uvlock          *handler.UVLock
+	gemfilelock     *handler.GemfileLock
+	cargotoml       *handler.CargoTOML
+	cargolock       *handler.CargoLock
+	pomxml          *handler.PomXML
+	buildgradle     *handler.BuildGradle
+	versioncatalog  *handler.VersionCatalog
+	composerjson    *handler.ComposerJSON
+	composerlock    *handler.ComposerLock
+	csproj          *handler.CSProj
+	packagesprops   *handler.DirectoryPackagesProps
+	nugetlock       *handler.PackagesLockJSON
+	workflow        *handler.GitHubWorkflow
 }
 
-func NewRouter(ghtoken string, transport http.RoundTripper) *Router {
+// NewRouter returns a router whose lockfile handlers include transitive dependencies if transitive is set.
+// Images are read with the registry credentials of dockerConfig, which may be nil.
+func NewRouter(ghtoken string, transport http.RoundTripper, transitive bool, dockerConfig *dockerconfig.Config) *Router {
 	hcli := &http.Client{
 		Transport: transport,
 	}
 	gcli := github.NewClient(hcli)
-	if ghtoken != "" {
+	if ct, ok := transport.(*CacheTransport); ok && ghtoken != "" {
+		// the cache passes requests with credentials through, so the token is set beneath it to keep GitHub responses cached
+		gcli = github.NewClient(&http.Client{
+			Transport: &CacheTransport{Transport: &tokenTransport{Transport: ct.Transport, Token: ghtoken}, Cache: ct.Cache},
+		})
+	} else if ghtoken != "" {
 		gcli = gcli.WithAuthToken(ghtoken)
 	}
 	return &Router{
-		gomod:           &handler.GoMod{GCli: gcli, HTTPClient: hcli},
+		gomod:           &handler.GoMod{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
 		packagejson:     &handler.PackageJSON{GCli: gcli, HTTPClient: hcli},
-		dockerfile:      &handler.Dockerfile{HTTPClient: hcli},
+		dockerfile:      &handler.Dockerfile{HTTPClient: hcli, DockerConfig: dockerConfig},
 		requirementstxt: &handler.RequirementsTXT{GCli: gcli, HTTPClient: hcli},
 		gemfile:         &handler.GemFile{GCli: gcli, HTTPClient: hcli},
+		packagelockjson: &handler.PackageLockJSON{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		yarnlock:        &handler.YarnLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		pnpmlock:        &handler.PNPMLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		runtimeversion:  &handler.RuntimeVersion{HTTPClient: hcli},
+		pyproject:       &handler.PyProject{GCli: gcli, HTTPClient: hcli},
+		poetrylock:      &handler.PoetryLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		pipfile:         &handler.Pipfile{GCli: gcli, HTTPClient: hcli},
+		pipfilelock:     &handler.PipfileLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		uvlock:          &handler.UVLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		gemfilelock:     &handler.GemfileLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		cargotoml:       &handler.CargoTOML{GCli: gcli, HTTPClient: hcli},
+		cargolock:       &handler.CargoLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		pomxml:          &handler.PomXML{GCli: gcli, HTTPClient: hcli},
+		buildgradle:     &handler.BuildGradle{GCli: gcli, HTTPClient: hcli},
+		versioncatalog:  &handler.VersionCatalog{GCli: gcli, HTTPClient: hcli},
+		composerjson:    &handler.ComposerJSON{GCli: gcli, HTTPClient: hcli},
+		composerlock:    &handler.ComposerLock{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		csproj:          &handler.CSProj{GCli: gcli, HTTPClient: hcli},
+		packagesprops:   &handler.DirectoryPackagesProps{GCli: gcli, HTTPClient: hcli},
+		nugetlock:       &handler.PackagesLockJSON{GCli: gcli, HTTPClient: hcli, Transitive: transitive},
+		workflow:        &handler.GitHubWorkflow{GCli: gcli, HTTPClient: hcli, DockerConfig: dockerConfig},
 	}
 }
 
+// tokenTransport sets a bearer token on requests.
+type tokenTransport struct {
+	Transport http.RoundTripper
+	Token     string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	return t.Transport.RoundTrip(req)
+}
+
+// Route returns the handler of the file at path, or nil if the file isn't supported.
 func (r *Router) Route(path string) handler.Handler {
-	path = strings.ToLower(path)
+	// workflows are told by their directory rather than their name
+	if dir := filepath.ToSlash(filepath.Dir(path)); dir == ".github/workflows" || strings.HasSuffix(dir, "/.github/workflows") {
+		if ext := filepath.Ext(path); ext == ".yml" || ext == ".yaml" {
+			return r.workflow
+		}
+		return nil
+	}
+	path = strings.ToLower(filepath.Base(path))
 	if strings.Contains(path, "go.mod") {
 		return r.gomod
 	}
+	if path == "package-lock.json" || path == "npm-shrinkwrap.json" {
+		return r.packagelockjson
+	}
+	if path == "yarn.lock" {
+		return r.yarnlock
+	}
+	if path == "pnpm-lock.yaml" {
+		return r.pnpmlock
+	}
+	switch path {
+	case ".nvmrc", ".node-version", ".python-version", ".ruby-version", ".tool-versions", "runtime.txt":
+		return r.runtimeversion
+	case "pyproject.toml":
+		return r.pyproject
+	case "poetry.lock":
+		return r.poetrylock
+	case "pipfile":
+		return r.pipfile
+	case "pipfile.lock":
+		return r.pipfilelock
+	case "uv.lock":
+		return r.uvlock
+	case "gemfile.lock", "gems.locked":
+		return r.gemfilelock
+	case "cargo.toml":
+		return r.cargotoml
+	case "cargo.lock":
+		return r.cargolock
+	case "pom.xml":
+		return r.pomxml
+	case "build.gradle", "build.gradle.kts":
+		return r.buildgradle
+	case "libs.versions.toml":
+		return r.versioncatalog
+	case "composer.json":
+		return r.composerjson
+	case "composer.lock":
+		return r.composerlock
+	case "directory.packages.props":
+		return r.packagesprops
+	case "packages.lock.json":
+		return r.nugetlock
+	}
+	if strings.HasSuffix(path, ".csproj") || strings.HasSuffix(path, ".fsproj") || strings.HasSuffix(path, ".vbproj") {
+		return r.csproj
+	}
 	if strings.Contains(path, "package.json") {
 		return r.packagejson
 	}
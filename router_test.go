@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/izziiyt/compaa/handler"
+	"gotest.tools/v3/assert"
+)
+
+func Test_Route(t *testing.T) {
+	r := NewRouter("", http.DefaultTransport, false, nil)
+	for _, tt := range []struct {
+		path string
+		want handler.Handler
+	}{
+		{path: ".github/workflows/ci.yml", want: r.workflow},
+		{path: "sub/.github/workflows/x.yaml", want: r.workflow},
+		{path: ".github/workflows/README.md"},
+		{path: ".github/dependabot.yml"},
+		{path: "sub/go.mod", want: r.gomod},
+		{path: "Gemfile.lock", want: r.gemfilelock},
+	} {
+		assert.Equal(t, r.Route(tt.path), tt.want, tt.path)
+	}
+}
@@ -0,0 +1,57 @@
+package cratesio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	baseURL = "https://crates.io/api/v1/crates"
+	// crates.io rejects requests without a user agent naming the client
+	userAgent = "compaa (https://github.com/izziiyt/compaa)"
+)
+
+type Response struct {
+	Crate struct {
+		Name          string `json:"name"`
+		Repository    string `json:"repository"`
+		Homepage      string `json:"homepage"`
+		Documentation string `json:"documentation"`
+		MaxVersion    string `json:"max_stable_version"`
+	} `json:"crate"`
+}
+
+func GetCrate(ctx context.Context, cli *http.Client, name string) (*Response, error) {
+	url := fmt.Sprintf("%s/%s", baseURL, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	res, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Response{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,184 @@
+package dockerconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dockerHubServer is the key Docker Hub credentials are stored under.
+const dockerHubServer = "https://index.docker.io/v1/"
+
+// Credential authenticates to a registry, either by username and password or by a token.
+type Credential struct {
+	Username string
+	Password string
+	// IdentityToken is an OAuth2 refresh token for the token service of the registry.
+	IdentityToken string
+	// RegistryToken is a bearer token sent to the registry as is.
+	RegistryToken string
+}
+
+type authEntry struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+	RegistryToken string `json:"registrytoken"`
+}
+
+// Config is the credential part of a docker client config.json.
+type Config struct {
+	Auths       map[string]*authEntry `json:"auths"`
+	CredsStore  string                `json:"credsStore"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+
+	mu    sync.Mutex
+	cache map[string]*Credential
+}
+
+// Load reads config.json in $DOCKER_CONFIG, or in ~/.docker. A missing file results in an empty config.
+func Load() (*Config, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%v: %w", filepath.Join(dir, "config.json"), err)
+	}
+	return c, nil
+}
+
+// Credential returns the credential for a registry host, or nil if there is none.
+// A registry in credHelpers is asked to its helper, then auths and credsStore are consulted in this order.
+// Credentials from helpers are remembered, so each helper runs once per host.
+func (c *Config) Credential(host string) (*Credential, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cred, ok := c.cache[host]; ok {
+		return cred, nil
+	}
+
+	cred, err := c.lookup(host)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache == nil {
+		c.cache = map[string]*Credential{}
+	}
+	c.cache[host] = cred
+	return cred, nil
+}
+
+func (c *Config) lookup(host string) (*Credential, error) {
+	server := host
+	if isDockerHub(host) {
+		server = dockerHubServer
+	}
+	for k, helper := range c.CredHelpers {
+		if sameHost(k, host) {
+			return runHelper(helper, server)
+		}
+	}
+	for k, e := range c.Auths {
+		if !sameHost(k, host) {
+			continue
+		}
+		if cred, err := e.credential(); cred != nil || err != nil {
+			return cred, err
+		}
+	}
+	if c.CredsStore != "" {
+		return runHelper(c.CredsStore, server)
+	}
+	return nil, nil
+}
+
+// credential returns nil for entries which only record a login into credsStore.
+func (e *authEntry) credential() (*Credential, error) {
+	cred := &Credential{
+		Username:      e.Username,
+		Password:      e.Password,
+		IdentityToken: e.IdentityToken,
+		RegistryToken: e.RegistryToken,
+	}
+	if e.Auth != "" {
+		b, err := base64.StdEncoding.DecodeString(e.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth in docker config: %w", err)
+		}
+		user, pass, ok := strings.Cut(string(b), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid auth in docker config")
+		}
+		cred.Username, cred.Password = user, pass
+	}
+	if *cred == (Credential{}) {
+		return nil, nil
+	}
+	return cred, nil
+}
+
+// runHelper gets the credential of server from docker-credential-<helper>.
+func runHelper(helper, server string) (*Credential, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	b, err := cmd.Output()
+	if err != nil {
+		// helpers print this to stdout with exit status 1
+		if strings.Contains(string(b), "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("docker-credential-%v: %w: %v", helper, err, strings.TrimSpace(stderr.String()+string(b)))
+	}
+	r := struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("docker-credential-%v: %w", helper, err)
+	}
+	if r.Username == "<token>" {
+		return &Credential{IdentityToken: r.Secret}, nil
+	}
+	return &Credential{Username: r.Username, Password: r.Secret}, nil
+}
+
+// sameHost reports whether a config key, which may be a URL like "https://index.docker.io/v1/", names host.
+func sameHost(key, host string) bool {
+	if i := strings.Index(key, "://"); i >= 0 {
+		key = key[i+3:]
+	}
+	key, _, _ = strings.Cut(key, "/")
+	if isDockerHub(host) {
+		return isDockerHub(key)
+	}
+	return key == host
+}
+
+func isDockerHub(host string) bool {
+	return host == "docker.io" || host == "index.docker.io" || host == "registry-1.docker.io"
+}
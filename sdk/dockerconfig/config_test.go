@@ -0,0 +1,62 @@
+package dockerconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const fakeHelper = `#!/bin/sh
+read server
+case "$server" in
+  helper.example) echo '{"ServerURL":"helper.example","Username":"<token>","Secret":"refresh"}' ;;
+  https://index.docker.io/v1/) echo '{"ServerURL":"https://index.docker.io/v1/","Username":"hub","Secret":"hubpass"}' ;;
+  *) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`
+
+func Test_Credential(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(fakeHelper), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{
+	"auths": {
+		"https://harbor.example/v2/": {"auth": "dXNlcjpwYXNz"},
+		"index.docker.io": {}
+	},
+	"credsStore": "fake",
+	"credHelpers": {"helper.example": "fake"}
+}`), 0644))
+
+	c, err := Load()
+	assert.NilError(t, err)
+
+	cred, err := c.Credential("harbor.example")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &Credential{Username: "user", Password: "pass"})
+
+	cred, err = c.Credential("helper.example")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &Credential{IdentityToken: "refresh"})
+
+	// the empty auths entry only records the login into credsStore
+	cred, err = c.Credential("docker.io")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &Credential{Username: "hub", Password: "hubpass"})
+
+	cred, err = c.Credential("ghcr.io")
+	assert.NilError(t, err)
+	assert.Assert(t, cred == nil)
+}
+
+func Test_LoadWithoutConfig(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	c, err := Load()
+	assert.NilError(t, err)
+	cred, err := c.Credential("ghcr.io")
+	assert.NilError(t, err)
+	assert.Assert(t, cred == nil)
+}
@@ -1,47 +1,142 @@
 package dockerhub
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
 )
 
 const baseURL = "https://hub.docker.com/v2"
 
 type Response struct {
+	Name          string    `json:"name"`
+	Digest        string    `json:"digest"`
+	Images        []*Image  `json:"images"`
 	LastUpdated   time.Time `json:"last_updated"`
 	TagLastPushed time.Time `json:"tag_last_pushed"`
 }
 
-func ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string) (*Response, error) {
+// Image is a platform image of a tag.
+type Image struct {
+	Digest     string    `json:"digest"`
+	LastPushed time.Time `json:"last_pushed"`
+}
+
+// ReadTag reads a tag of a repository. Private repositories need cred with a username and a password or an access token.
+func ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string, cred *dockerconfig.Credential) (*Response, error) {
 	url := fmt.Sprintf("%s/namespaces/%s/repositories/%s/tags/%s", baseURL, namespace, repository, tag)
+	r := &Response{}
+	if err := get(ctx, cli, url, cred, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListTags reads every tag of a repository, following the pages of the listing.
+func ListTags(ctx context.Context, cli *http.Client, namespace, repository string, cred *dockerconfig.Credential) ([]*Response, error) {
+	url := fmt.Sprintf("%s/namespaces/%s/repositories/%s/tags?page_size=100", baseURL, namespace, repository)
+	return listTags(ctx, cli, url, 0, cred)
+}
+
+// ListRecentTags reads the limit most recently updated tags of a repository, newest first.
+func ListRecentTags(ctx context.Context, cli *http.Client, namespace, repository string, limit int, cred *dockerconfig.Credential) ([]*Response, error) {
+	url := fmt.Sprintf("%s/namespaces/%s/repositories/%s/tags?page_size=100&ordering=last_updated", baseURL, namespace, repository)
+	return listTags(ctx, cli, url, limit, cred)
+}
+
+// listTags follows the pages of a listing until limit tags are read. A limit of 0 reads every page.
+func listTags(ctx context.Context, cli *http.Client, url string, limit int, cred *dockerconfig.Credential) ([]*Response, error) {
+	var rs []*Response
+	for url != "" && (limit == 0 || len(rs) < limit) {
+		page := struct {
+			Next    string      `json:"next"`
+			Results []*Response `json:"results"`
+		}{}
+		if err := get(ctx, cli, url, cred, &page); err != nil {
+			return nil, err
+		}
+		rs = append(rs, page.Results...)
+		url = page.Next
+	}
+	if limit > 0 && len(rs) > limit {
+		rs = rs[:limit]
+	}
+	return rs, nil
+}
+
+func get(ctx context.Context, cli *http.Client, url string, cred *dockerconfig.Credential, v any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if cred != nil && cred.Username != "" {
+		token, err := login(ctx, cli, cred)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 	res, err := cli.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
 		//nolint:errcheck
 		io.Copy(io.Discard, res.Body)
-		return nil, fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+		return fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
 	}
 
 	b, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return json.Unmarshal(b, v)
+}
 
-	r := &Response{}
-	if err := json.Unmarshal(b, r); err != nil {
-		return nil, err
+// tokens remembers the token of each username.
+var tokens = sync.Map{}
+
+// login exchanges a username and a password for a token of the Docker Hub API.
+func login(ctx context.Context, cli *http.Client, cred *dockerconfig.Credential) (string, error) {
+	if t, ok := tokens.Load(cred.Username); ok {
+		return t.(string), nil
 	}
-	return r, nil
+	body, err := json.Marshal(map[string]string{"username": cred.Username, "password": cred.Password})
+	if err != nil {
+		return "", err
+	}
+	url := baseURL + "/users/login"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := cli.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return "", fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	}
+	r := struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return "", err
+	}
+	tokens.Store(cred.Username, r.Token)
+	return r.Token, nil
 }
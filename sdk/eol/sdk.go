@@ -38,14 +38,17 @@ func NewCycleDetail(cd *cycleDetail) (CD *CycleDetail, err error) {
 		CD = nil
 		return
 	}
-	CD.LatestReleaseDate, err = time.Parse(time.DateOnly, cd.LatestReleaseDate)
-	if err != nil {
-		CD = nil
-		return
+	// some products don't track the date of the latest release
+	if cd.LatestReleaseDate != "" {
+		CD.LatestReleaseDate, err = time.Parse(time.DateOnly, cd.LatestReleaseDate)
+		if err != nil {
+			CD = nil
+			return
+		}
 	}
 	switch v := cd.EOL.(type) {
 	case bool:
-		CD.EOL = false
+		CD.EOL = v
 	case string:
 		CD.EOL = false
 		CD.EOLDate, err = time.Parse(time.DateOnly, v)
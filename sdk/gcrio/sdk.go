@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"path"
 	"slices"
 	"strconv"
 	"time"
+
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+	"github.com/izziiyt/compaa/sdk/oci"
 )
 
-const baseURL = "https://gcr.io/v2"
+const registry = "gcr.io"
 
 type _response struct {
 	Manifest map[string]*Response `json:"manifest"`
@@ -23,25 +26,23 @@ type Response struct {
 	Uploaded       time.Time
 }
 
-func ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string) (*Response, error) {
-	url := fmt.Sprintf("%s/%s/%s/tags/list", baseURL, namespace, repository)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	res, err := cli.Do(req)
+// ReadTag reads the upload time of tag from the tag list, which gcr.io extends with manifest details.
+func ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag string, cred *dockerconfig.Credential) (*Response, error) {
+	ms, err := ListManifests(ctx, cli, namespace, repository, cred)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		//nolint:errcheck
-		io.Copy(io.Discard, res.Body)
-		return nil, fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	for _, v := range ms {
+		if slices.Contains(v.Tag, tag) {
+			return v, nil
+		}
 	}
+	return nil, fmt.Errorf("tag %v not found", tag)
+}
 
-	b, err := io.ReadAll(res.Body)
+// ListManifests returns the manifests of a repository by digest.
+func ListManifests(ctx context.Context, cli *http.Client, namespace, repository string, cred *dockerconfig.Credential) (map[string]*Response, error) {
+	b, err := oci.Get(ctx, cli, registry, path.Join(namespace, repository), "tags/list", cred)
 	if err != nil {
 		return nil, err
 	}
@@ -52,18 +53,11 @@ func ReadTag(ctx context.Context, cli *http.Client, namespace, repository, tag s
 	}
 
 	for _, v := range r.Manifest {
-		if slices.Contains(v.Tag, tag) {
-			i, err := strconv.Atoi(v.TimeUploadedMS)
-			if err != nil {
-				return nil, err
-			}
-			return &Response{
-				Tag:            v.Tag,
-				TimeUploadedMS: v.TimeUploadedMS,
-				Uploaded:       time.Unix(0, int64(i)*int64(time.Millisecond)),
-			}, nil
+		i, err := strconv.Atoi(v.TimeUploadedMS)
+		if err != nil {
+			return nil, err
 		}
+		v.Uploaded = time.Unix(0, int64(i)*int64(time.Millisecond))
 	}
-
-	return nil, fmt.Errorf("tag %v not found", tag)
+	return r.Manifest, nil
 }
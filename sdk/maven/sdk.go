@@ -0,0 +1,86 @@
+package maven
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const baseURL = "https://repo1.maven.org/maven2"
+
+type Parent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// Response is the part of a POM which tells where the source of an artifact is.
+type Response struct {
+	URL string `xml:"url"`
+	SCM struct {
+		URL        string `xml:"url"`
+		Connection string `xml:"connection"`
+	} `xml:"scm"`
+	Parent *Parent `xml:"parent"`
+}
+
+// GetPOM reads the POM of a version of an artifact.
+func GetPOM(ctx context.Context, cli *http.Client, groupID, artifactID, version string) (*Response, error) {
+	url := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", baseURL, strings.ReplaceAll(groupID, ".", "/"), artifactID, version, artifactID, version)
+	r := &Response{}
+	if err := get(ctx, cli, url, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LatestVersion reads the latest release of an artifact from its maven-metadata.xml.
+func LatestVersion(ctx context.Context, cli *http.Client, groupID, artifactID string) (string, error) {
+	url := fmt.Sprintf("%s/%s/%s/maven-metadata.xml", baseURL, strings.ReplaceAll(groupID, ".", "/"), artifactID)
+	r := struct {
+		Versioning struct {
+			Latest   string   `xml:"latest"`
+			Release  string   `xml:"release"`
+			Versions []string `xml:"versions>version"`
+		} `xml:"versioning"`
+	}{}
+	if err := get(ctx, cli, url, &r); err != nil {
+		return "", err
+	}
+	switch {
+	case r.Versioning.Release != "":
+		return r.Versioning.Release, nil
+	case r.Versioning.Latest != "":
+		return r.Versioning.Latest, nil
+	case len(r.Versioning.Versions) > 0:
+		return r.Versioning.Versions[len(r.Versioning.Versions)-1], nil
+	}
+	return "", fmt.Errorf("no version of %v:%v found", groupID, artifactID)
+}
+
+func get(ctx context.Context, cli *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(b, v)
+}
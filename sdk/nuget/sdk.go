@@ -0,0 +1,86 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const baseURL = "https://api.nuget.org/v3-flatcontainer"
+
+// Response is the part of a nuspec which tells where the source of a package is.
+type Response struct {
+	Metadata struct {
+		ID         string `xml:"id"`
+		Version    string `xml:"version"`
+		ProjectURL string `xml:"projectUrl"`
+		Repository struct {
+			Type string `xml:"type,attr"`
+			URL  string `xml:"url,attr"`
+		} `xml:"repository"`
+	} `xml:"metadata"`
+}
+
+// GetNuspec reads the nuspec of a version of a package.
+func GetNuspec(ctx context.Context, cli *http.Client, id, version string) (*Response, error) {
+	id, version = strings.ToLower(id), strings.ToLower(version)
+	url := fmt.Sprintf("%s/%s/%s/%s.nuspec", baseURL, id, version, id)
+	b, err := get(ctx, cli, url)
+	if err != nil {
+		return nil, err
+	}
+	r := &Response{}
+	if err := xml.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LatestVersion reads the latest stable version of a package, or the latest prerelease if there is no stable one.
+func LatestVersion(ctx context.Context, cli *http.Client, id string) (string, error) {
+	url := fmt.Sprintf("%s/%s/index.json", baseURL, strings.ToLower(id))
+	b, err := get(ctx, cli, url)
+	if err != nil {
+		return "", err
+	}
+	r := struct {
+		Versions []string `json:"versions"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", err
+	}
+	// versions are listed in ascending order
+	for i := len(r.Versions) - 1; i >= 0; i-- {
+		if !strings.Contains(r.Versions[i], "-") {
+			return r.Versions[i], nil
+		}
+	}
+	if len(r.Versions) > 0 {
+		return r.Versions[len(r.Versions)-1], nil
+	}
+	return "", fmt.Errorf("no version of %v found", id)
+}
+
+func get(ctx context.Context, cli *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,405 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+)
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+type Response struct {
+	Digest  string
+	Created time.Time
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+// manifest is either an image index, which lists Manifests, or an image manifest, which has a Config.
+type manifest struct {
+	Manifests []descriptor `json:"manifests"`
+	Config    *descriptor  `json:"config"`
+}
+
+type config struct {
+	Created time.Time `json:"created"`
+}
+
+// session talks to a repository of a registry and remembers how the registry asked to authenticate.
+type session struct {
+	cli        *http.Client
+	registry   string
+	repository string
+	cred       *dockerconfig.Credential
+	token      string
+	basic      bool
+}
+
+func newSession(cli *http.Client, registry, repository string, cred *dockerconfig.Credential) *session {
+	s := &session{cli: cli, registry: registry, repository: repository, cred: cred}
+	if cred != nil {
+		s.token = cred.RegistryToken
+	}
+	return s
+}
+
+// Get requests a path under /v2/<repository>/ of registry and returns the body.
+// It authenticates with cred, which may be nil, the way the registry asks for.
+func Get(ctx context.Context, cli *http.Client, registry, repository, path string, cred *dockerconfig.Credential) ([]byte, error) {
+	r, err := newSession(cli, registry, repository, cred).get(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+	return r.body, nil
+}
+
+// ReadTag returns the creation time of the image tag points to, read from the image config.
+// tag may also be a digest like "@sha256:xxxx". Image indexes are resolved to their linux/amd64 image.
+func ReadTag(ctx context.Context, cli *http.Client, registry, repository, tag string, cred *dockerconfig.Credential) (*Response, error) {
+	s := newSession(cli, registry, repository, cred)
+
+	ref := strings.TrimPrefix(tag, "@")
+	m, digest, err := s.manifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if m.Config == nil {
+		d, err := platformManifest(m.Manifests)
+		if err != nil {
+			return nil, err
+		}
+		if m, _, err = s.manifest(ctx, d); err != nil {
+			return nil, err
+		}
+		if m.Config == nil {
+			return nil, fmt.Errorf("manifest %v of %v has no config", d, repository)
+		}
+	}
+
+	b, err := s.get(ctx, "blobs/"+m.Config.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	c := &config{}
+	if err := json.Unmarshal(b.body, c); err != nil {
+		return nil, err
+	}
+	if c.Created.IsZero() {
+		return nil, fmt.Errorf("image config of %v:%v has no created timestamp", repository, tag)
+	}
+	return &Response{Digest: digest, Created: c.Created}, nil
+}
+
+// platformManifest picks the linux/amd64 image of an index, or the first image if there is none.
+func platformManifest(ds []descriptor) (string, error) {
+	var first string
+	for _, d := range ds {
+		// attestation manifests have the "unknown" platform
+		if d.Platform == nil || d.Platform.OS == "unknown" {
+			continue
+		}
+		if d.Platform.OS == "linux" && d.Platform.Architecture == "amd64" {
+			return d.Digest, nil
+		}
+		if first == "" {
+			first = d.Digest
+		}
+	}
+	if first == "" {
+		return "", fmt.Errorf("image index has no image manifest")
+	}
+	return first, nil
+}
+
+// manifest returns the manifest at ref and its digest, which is the hash of the exact bytes served.
+func (s *session) manifest(ctx context.Context, ref string) (*manifest, string, error) {
+	r, err := s.get(ctx, "manifests/"+ref, strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return nil, "", err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(r.body, m); err != nil {
+		return nil, "", err
+	}
+	return m, fmt.Sprintf("sha256:%x", sha256.Sum256(r.body)), nil
+}
+
+// ListTags returns the tags of a repository, following the pages of the listing.
+func ListTags(ctx context.Context, cli *http.Client, registry, repository string, cred *dockerconfig.Credential) ([]string, error) {
+	return newSession(cli, registry, repository, cred).listTags(ctx)
+}
+
+var nextLinkRegexp = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+func (s *session) listTags(ctx context.Context) ([]string, error) {
+	var tags []string
+	u := s.url("tags/list")
+	for u != "" {
+		r, err := s.do(ctx, http.MethodGet, u, "")
+		if err != nil {
+			return nil, err
+		}
+		page := struct {
+			Tags []string `json:"tags"`
+		}{}
+		if err := json.Unmarshal(r.body, &page); err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		// registries which page the listing link the next page like </v2/<repository>/tags/list?n=100&last=x>; rel="next"
+		u = ""
+		if m := nextLinkRegexp.FindStringSubmatch(r.header.Get("Link")); m != nil {
+			base, _ := url.Parse(r.url)
+			next, err := base.Parse(m[1])
+			if err != nil {
+				return nil, err
+			}
+			if next.String() != r.url {
+				u = next.String()
+			}
+		}
+	}
+	return tags, nil
+}
+
+// MaxDigestTags caps the tags checked for a digest, so that repositories with thousands of tags don't cost thousands of requests.
+const MaxDigestTags = 200
+
+// DigestTags returns which of tags, or of all tags of the repository if tags is empty, currently point at digest.
+// A digest pinned for one platform matches the tag of the index listing it. Tags are compared by HEAD requests,
+// which don't count against pull rate limits, and indexes are only read if no tag points at digest as a whole.
+// Tags which can't be read are skipped, and only the last MaxDigestTags tags are checked.
+func DigestTags(ctx context.Context, cli *http.Client, registry, repository, digest string, tags []string, cred *dockerconfig.Credential) ([]string, error) {
+	s := newSession(cli, registry, repository, cred)
+	if len(tags) == 0 {
+		var err error
+		if tags, err = s.listTags(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(tags) > MaxDigestTags {
+		tags = tags[len(tags)-MaxDigestTags:]
+	}
+
+	var live, indexes []string
+	var lastErr error
+	read := false
+	accept := strings.Join(manifestMediaTypes, ", ")
+	for _, t := range tags {
+		r, err := s.do(ctx, http.MethodHead, s.url("manifests/"+t), accept)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		read = true
+		switch d := r.header.Get("Docker-Content-Digest"); {
+		case d == digest:
+			live = append(live, t)
+		case d == "" || isIndex(r.header.Get("Content-Type")):
+			indexes = append(indexes, t)
+		}
+	}
+	if len(live) > 0 {
+		return live, nil
+	}
+	for _, t := range indexes {
+		m, d, err := s.manifest(ctx, t)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		read = true
+		if d == digest || slices.ContainsFunc(m.Manifests, func(c descriptor) bool { return c.Digest == digest }) {
+			live = append(live, t)
+		}
+	}
+	if !read && lastErr != nil {
+		// no tag could be read, which doesn't tell the digest has drifted
+		return nil, lastErr
+	}
+	return live, nil
+}
+
+func isIndex(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "application/vnd.oci.image.index.v1+json") ||
+		strings.HasPrefix(mediaType, "application/vnd.docker.distribution.manifest.list.v2+json")
+}
+
+type response struct {
+	url    string
+	header http.Header
+	body   []byte
+}
+
+// url returns the URL of a path under the repository.
+func (s *session) url(path string) string {
+	return fmt.Sprintf("https://%s/v2/%s/%s", s.registry, s.repository, path)
+}
+
+// get requests a path under the repository, and authenticates once the registry asks for it.
+func (s *session) get(ctx context.Context, path, accept string) (*response, error) {
+	return s.do(ctx, http.MethodGet, s.url(path), accept)
+}
+
+// do requests u with method, and authenticates once the registry asks for it.
+func (s *session) do(ctx context.Context, method, u, accept string) (*response, error) {
+	for retried := false; ; retried = true {
+		req, err := http.NewRequestWithContext(ctx, method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if s.basic {
+			req.SetBasicAuth(s.cred.Username, s.cred.Password)
+		} else if s.token != "" {
+			req.Header.Set("Authorization", "Bearer "+s.token)
+		}
+		res, err := s.cli.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		b, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode == http.StatusUnauthorized && !retried {
+			if err := s.authorize(ctx, res.Header.Get("WWW-Authenticate")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("something wrong with accesing :%v %v", u, res.StatusCode)
+		}
+		return &response{url: u, header: res.Header, body: b}, nil
+	}
+}
+
+// authorize answers a Basic challenge with the credential, and a Bearer challenge by requesting a pull token from its realm.
+// Without a credential the token is requested anonymously.
+func (s *session) authorize(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if strings.EqualFold(scheme, "Basic") {
+		if s.cred == nil || s.cred.Username == "" {
+			return fmt.Errorf("no credential for %v", s.registry)
+		}
+		s.basic = true
+		return nil
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("unsupported authentication %q of %v", scheme, s.registry)
+	}
+	ps := parseChallenge(params)
+	realm, err := url.Parse(ps["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid realm in %q", challenge)
+	}
+	q := realm.Query()
+	if ps["service"] != "" {
+		q.Set("service", ps["service"])
+	}
+	scope := ps["scope"]
+	if scope == "" {
+		scope = "repository:" + s.repository + ":pull"
+	}
+	q.Set("scope", scope)
+
+	var req *http.Request
+	if s.cred != nil && s.cred.IdentityToken != "" {
+		// OAuth2 token request with the refresh token docker login has stored
+		q.Set("grant_type", "refresh_token")
+		q.Set("refresh_token", s.cred.IdentityToken)
+		q.Set("client_id", "compaa")
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, realm.String(), strings.NewReader(q.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		realm.RawQuery = q.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return err
+		}
+		if s.cred != nil && s.cred.Username != "" {
+			req.SetBasicAuth(s.cred.Username, s.cred.Password)
+		}
+	}
+	res, err := s.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("something wrong with accesing :%v %v", realm, res.StatusCode)
+	}
+	t := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&t); err != nil {
+		return err
+	}
+	s.token = t.Token
+	if s.token == "" {
+		s.token = t.AccessToken
+	}
+	if s.token == "" {
+		return fmt.Errorf("no token granted by %v", realm.Host)
+	}
+	return nil
+}
+
+// parseChallenge parses the `key="value",key="value"` parameters of a WWW-Authenticate header.
+func parseChallenge(s string) map[string]string {
+	ps := map[string]string{}
+	for s != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(s, " ,"), "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		ps[strings.ToLower(strings.TrimSpace(key))] = value
+		s = rest
+	}
+	return ps
+}
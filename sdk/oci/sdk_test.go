@@ -0,0 +1,179 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izziiyt/compaa/sdk/dockerconfig"
+	"gotest.tools/v3/assert"
+)
+
+const index = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+	{"digest":"sha256:attestation","platform":{"os":"unknown","architecture":"unknown"}},
+	{"digest":"sha256:arm64","platform":{"os":"linux","architecture":"arm64"}},
+	{"digest":"sha256:amd64","platform":{"os":"linux","architecture":"amd64"}}]}`
+
+func newRegistry(t *testing.T) *httptest.Server {
+	srv, _ := newCountingRegistry(t)
+	return srv
+}
+
+// newCountingRegistry also returns the number of token requests the registry has answered.
+func newCountingRegistry(t *testing.T) (*httptest.Server, *int) {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	tokens := 0
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		if r.URL.Query().Get("service") != "stand-in" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Query().Get("scope") {
+		case "repository:sample/app:pull":
+			fmt.Fprint(w, `{"token":"t0k"}`)
+		case "repository:private/app:pull":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"priv"}`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	mux.HandleFunc("/v2/private/app/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer priv" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%v/token",service="stand-in",scope="repository:private/app:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"tags":["1.0"]}`)
+	})
+	mux.HandleFunc("/v2/sample/app/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0k" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%v/token",service="stand-in",scope="repository:sample/app:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/v2/sample/app/") {
+		case "manifests/1.0":
+			assert.Assert(t, strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json"))
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(index))))
+			fmt.Fprint(w, index)
+		case "tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/sample/app/tags/list?n=2&last=1.0>; rel="next"`)
+				fmt.Fprint(w, `{"name":"sample/app","tags":["0.9","1.0"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"name":"sample/app","tags":["latest"]}`)
+		case "manifests/sha256:amd64":
+			fmt.Fprint(w, `{"schemaVersion":2,"config":{"digest":"sha256:config"}}`)
+		case "blobs/sha256:config":
+			// blobs are often served from a storage backend
+			http.Redirect(w, r, "/storage/config", http.StatusTemporaryRedirect)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/storage/config", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"created":"2024-01-02T03:04:05Z","architecture":"amd64"}`)
+	})
+	srv = httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &tokens
+}
+
+func Test_ReadTag(t *testing.T) {
+	srv := newRegistry(t)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	r, err := ReadTag(context.Background(), srv.Client(), registry, "sample/app", "1.0", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, r.Digest, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(index))))
+	assert.Equal(t, r.Created, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	_, err = ReadTag(context.Background(), srv.Client(), registry, "sample/app", "2.0", nil)
+	assert.ErrorContains(t, err, "404")
+}
+
+func Test_ListTags(t *testing.T) {
+	srv := newRegistry(t)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	tags, err := ListTags(context.Background(), srv.Client(), registry, "sample/app", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tags, []string{"0.9", "1.0", "latest"})
+}
+
+func Test_DigestTags(t *testing.T) {
+	srv, tokens := newCountingRegistry(t)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+	ctx := context.Background()
+
+	// 0.9 and latest can't be read and are skipped
+	live, err := DigestTags(ctx, srv.Client(), registry, "sample/app", fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(index))), nil, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, live, []string{"1.0"})
+	assert.Equal(t, *tokens, 1)
+
+	// a platform image matches the tag of its index
+	live, err = DigestTags(ctx, srv.Client(), registry, "sample/app", "sha256:arm64", nil, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, live, []string{"1.0"})
+
+	live, err = DigestTags(ctx, srv.Client(), registry, "sample/app", "sha256:gone", []string{"1.0"}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(live), 0)
+
+	_, err = DigestTags(ctx, srv.Client(), registry, "sample/app", "sha256:gone", []string{"2.0"}, nil)
+	assert.ErrorContains(t, err, "404")
+}
+
+func Test_GetWithCredential(t *testing.T) {
+	srv := newRegistry(t)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	_, err := Get(context.Background(), srv.Client(), registry, "private/app", "tags/list", nil)
+	assert.ErrorContains(t, err, "401")
+
+	b, err := Get(context.Background(), srv.Client(), registry, "private/app", "tags/list", &dockerconfig.Credential{Username: "user", Password: "pass"})
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `{"tags":["1.0"]}`)
+}
+
+func Test_GetWithBasicChallenge(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="harbor"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	_, err := Get(context.Background(), srv.Client(), registry, "private/app", "tags/list", nil)
+	assert.ErrorContains(t, err, "no credential")
+
+	_, err = Get(context.Background(), srv.Client(), registry, "private/app", "tags/list", &dockerconfig.Credential{Username: "user", Password: "pass"})
+	assert.NilError(t, err)
+}
+
+func Test_ParseChallenge(t *testing.T) {
+	ps := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull"`)
+	assert.DeepEqual(t, ps, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/alpine:pull",
+	})
+}
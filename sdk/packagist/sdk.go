@@ -0,0 +1,63 @@
+package packagist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const baseURL = "https://repo.packagist.org/p2"
+
+type Source struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference"`
+}
+
+// Response is the latest version of a package.
+type Response struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Source   Source `json:"source"`
+	Homepage string `json:"homepage"`
+}
+
+// GetPackage reads the metadata of a package like "laravel/framework". Versions are listed newest first,
+// and the newest one has every field while older ones only have the fields that changed.
+func GetPackage(ctx context.Context, cli *http.Client, name string) (*Response, error) {
+	url := fmt.Sprintf("%s/%s.json", baseURL, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		//nolint:errcheck
+		io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("something wrong with accesing :%v %v", url, res.StatusCode)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r := struct {
+		Packages map[string][]*Response `json:"packages"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	versions := r.Packages[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no version of %v found", name)
+	}
+	return versions[0], nil
+}